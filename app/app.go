@@ -39,7 +39,7 @@ func (a *App) Initialize() {
 
 	// Handler instance
 	a.Handler = new(handlers.Handler)
-	err = a.Handler.Initialize(a.Conf.DbURL, a.Conf.Salt)
+	err = a.Handler.Initialize(a.Conf)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -83,70 +83,163 @@ func (a *App) initializeMiddleware() {
 func (a *App) initializeRoutes() {
 	r := a.Router
 
-	// Welcome
-	r.Get("/", a.Handler.Hello)
+	// Information routes
+	r.Group(func(r chi.Router) {
+		if !a.Conf.PublicInfo {
+			r.Use(a.Handler.Authenticate)
+		}
 
-	// Version
-	r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
-		handlers.VersionSwagger() // Prevent function not used warning
-		handlers.RespondJSON(w, r, http.StatusOK, handlers.StatusResponse{
-			Code:    strconv.Itoa(http.StatusOK),
-			Message: a.Version,
+		// Welcome
+		r.Get("/", a.Handler.Hello)
+
+		// Version
+		r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
+			handlers.VersionSwagger() // Prevent function not used warning
+			handlers.RespondJSON(w, r, http.StatusOK, handlers.StatusResponse{
+				Code:    strconv.Itoa(http.StatusOK),
+				Message: a.Version,
+			})
+		})
+
+		// Swagger
+		r.Route("/swagger", func(r chi.Router) {
+			r.Get("/*", httpSwagger.Handler(
+				httpSwagger.DocExpansion("none"),
+			))
 		})
 	})
 
+	// Resource routes
+	r.Group(func(r chi.Router) {
+		r.Use(a.Handler.Authenticate)
+		r.Use(a.Handler.ConditionalGet)
+		r.Group(func(r chi.Router) {
+			r.Use(a.Handler.WriteTx(a.txRoutes))
+			a.initializeResourceRoutes(r)
+		})
+		r.Post("/batch", a.Handler.Batch(a.txRoutes))
+
+		// Key rotation changes keys of process and is not available in batch
+		r.With(a.Handler.AuthorizeAdminWrite).Post("/config/rotate_key", a.Handler.RotateEncryptionKey)
+		r.Post("/import/{resource}", a.Handler.Import)
+		r.Get("/topology", a.Handler.GetTopology)
+	})
+
+	// Streaming routes. Responses are not buffered for ETag
+	r.Group(func(r chi.Router) {
+		r.Use(a.Handler.Authenticate)
+		r.Get("/export/{resource}", a.Handler.Export)
+	})
+
+	// Custom 404 handler
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		handlers.RespondError(w, r, http.StatusNotFound, "Route does not exist")
+	})
+
+	// Custom 405 handler
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		handlers.RespondError(w, r, http.StatusMethodNotAllowed, "Method is not valid")
+	})
+
+}
+
+// Resource routes using handler h. Used for write requests and batch operations in transaction
+func (a *App) txRoutes(h *handlers.Handler) http.Handler {
+	b := *a
+	b.Handler = h
+
+	r := chi.NewRouter()
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		handlers.RespondError(w, r, http.StatusNotFound, "Route does not exist")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		handlers.RespondError(w, r, http.StatusMethodNotAllowed, "Method is not valid")
+	})
+	b.initializeResourceRoutes(r)
+
+	return r
+}
+
+// Resource route definitions
+func (a *App) initializeResourceRoutes(r chi.Router) {
 	// Routes for "/archived/interfaces" resource
 	r.Route("/archived/interfaces", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetArchivedInterfaces)
 		r.Get("/count", a.Handler.CountArchivedInterfaces)
-		r.Post("/", a.Handler.CreateArchivedInterface)
+		r.With(a.Handler.Audit(handlers.AuditArchivedInterface)).Post("/", a.Handler.CreateArchivedInterface)
 
 		// Subroutes
 		r.Route("/{ifa_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditArchivedInterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetArchivedInterface))
+
 			r.Get("/", a.Handler.GetArchivedInterface)
 			r.Put("/", a.Handler.UpdateArchivedInterface)
+			r.Patch("/", a.Handler.PatchArchivedInterface)
 			r.Delete("/", a.Handler.DeleteArchivedInterface)
 		})
 	})
 
 	// Routes for "/archived/subinterfaces" resource
 	r.Route("/archived/subinterfaces", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetArchivedSubinterfaces)
 		r.Get("/count", a.Handler.CountArchivedSubinterfaces)
-		r.Post("/", a.Handler.CreateArchivedSubinterface)
+		r.With(a.Handler.Audit(handlers.AuditArchivedSubinterface)).Post("/", a.Handler.CreateArchivedSubinterface)
 
 		// Subroutes
-		r.Route("/{ifa_id:[0-9]+}", func(r chi.Router) {
+		r.Route("/{sifa_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditArchivedSubinterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetArchivedSubinterface))
+
 			r.Get("/", a.Handler.GetArchivedSubinterface)
 			r.Put("/", a.Handler.UpdateArchivedSubinterface)
+			r.Patch("/", a.Handler.PatchArchivedSubinterface)
 			r.Delete("/", a.Handler.DeleteArchivedSubinterface)
 		})
 	})
 	// Routes for "/config" resource
 	// Routes for "/config/credentials" resource
 	r.Route("/config/credentials", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetCredentials)
 		r.Get("/count", a.Handler.CountCredentials)
-		r.Post("/", a.Handler.CreateCredential)
+		r.With(a.Handler.Audit(handlers.AuditCredential)).Post("/", a.Handler.CreateCredential)
 
 		// Subroutes
 		r.Route("/{cred_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditCredential))
+			r.Use(a.Handler.IfMatch(a.Handler.GetCredential))
+
 			r.Get("/", a.Handler.GetCredential)
+			r.With(a.Handler.AuthorizeReveal).Get("/reveal", a.Handler.RevealCredential)
 			r.Put("/", a.Handler.UpdateCredential)
+			r.Patch("/", a.Handler.PatchCredential)
 			r.Delete("/", a.Handler.DeleteCredential)
 		})
 	})
 
 	// Routes for "/config/snmp_credentials" resource
 	r.Route("/config/snmp_credentials", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetSnmpCredentials)
 		r.Get("/count", a.Handler.CountSnmpCredentials)
-		r.Post("/", a.Handler.CreateSnmpCredential)
+		r.With(a.Handler.Audit(handlers.AuditSnmpCredential)).Post("/", a.Handler.CreateSnmpCredential)
 
 		// Subroutes
 		r.Route("/{snmp_cred_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditSnmpCredential))
+			r.Use(a.Handler.IfMatch(a.Handler.GetSnmpCredential))
+
 			r.Get("/", a.Handler.GetSnmpCredential)
+			r.With(a.Handler.AuthorizeReveal).Get("/reveal", a.Handler.RevealSnmpCredential)
 			r.Put("/", a.Handler.UpdateSnmpCredential)
+			r.Patch("/", a.Handler.PatchSnmpCredential)
 			r.Delete("/", a.Handler.DeleteSnmpCredential)
 			r.Get("/main_devices", a.Handler.GetSnmpCredentialsMainDevices)
 			r.Get("/ro_devices", a.Handler.GetSnmpCredentialsRoDevices)
@@ -155,28 +248,40 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/config/vars" resource
 	r.Route("/config/vars", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetVars)
 		r.Get("/count", a.Handler.CountVars)
-		r.Post("/", a.Handler.CreateVar)
+		r.With(a.Handler.Audit(handlers.AuditVar)).Post("/", a.Handler.CreateVar)
 
 		// Subroutes
 		r.Route("/{descr:\\w+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditVar))
+			r.Use(a.Handler.IfMatch(a.Handler.GetVar))
+
 			r.Get("/", a.Handler.GetVar)
 			r.Put("/", a.Handler.UpdateVar)
+			r.Patch("/", a.Handler.PatchVar)
 			r.Delete("/", a.Handler.DeleteVar)
 		})
 	})
 
 	// Routes for "/connections" resource
 	r.Route("/connections", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetConnections)
 		r.Get("/count", a.Handler.CountConnections)
-		r.Post("/", a.Handler.CreateConnection)
+		r.With(a.Handler.Audit(handlers.AuditConnection)).Post("/", a.Handler.CreateConnection)
 
 		// Subroutes
 		r.Route("/{con_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditConnection))
+			r.Use(a.Handler.IfMatch(a.Handler.GetConnection))
+
 			r.Get("/", a.Handler.GetConnection)
 			r.Put("/", a.Handler.UpdateConnection)
+			r.Patch("/", a.Handler.PatchConnection)
 			r.Delete("/", a.Handler.DeleteConnection)
 			r.Get("/capacity", a.Handler.GetConnectionConCapacitiy)
 			r.Get("/class", a.Handler.GetConnectionConClass)
@@ -189,14 +294,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/connections/capacities" resource
 	r.Route("/connections/capacities", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetConCapacities)
 		r.Get("/count", a.Handler.CountConCapacities)
-		r.Post("/", a.Handler.CreateConCapacity)
+		r.With(a.Handler.Audit(handlers.AuditConCapacity)).Post("/", a.Handler.CreateConCapacity)
 
 		// Subroutes
 		r.Route("/{con_cap_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditConCapacity))
+			r.Use(a.Handler.IfMatch(a.Handler.GetConCapacity))
+
 			r.Get("/", a.Handler.GetConCapacity)
 			r.Put("/", a.Handler.UpdateConCapacity)
+			r.Patch("/", a.Handler.PatchConCapacity)
 			r.Delete("/", a.Handler.DeleteConCapacity)
 			r.Get("/connections", a.Handler.GetConCapacityConnections)
 		})
@@ -204,14 +315,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/connections/classes" resource
 	r.Route("/connections/classes", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetConClasses)
 		r.Get("/count", a.Handler.CountConClasses)
-		r.Post("/", a.Handler.CreateConClass)
+		r.With(a.Handler.Audit(handlers.AuditConClass)).Post("/", a.Handler.CreateConClass)
 
 		// Subroutes
 		r.Route("/{con_class_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditConClass))
+			r.Use(a.Handler.IfMatch(a.Handler.GetConClass))
+
 			r.Get("/", a.Handler.GetConClass)
 			r.Put("/", a.Handler.UpdateConClass)
+			r.Patch("/", a.Handler.PatchConClass)
 			r.Delete("/", a.Handler.DeleteConClass)
 			r.Get("/connections", a.Handler.GetConClassConnections)
 		})
@@ -219,14 +336,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/connections/providers" resource
 	r.Route("/connections/providers", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetConProviders)
 		r.Get("/count", a.Handler.CountConProviders)
-		r.Post("/", a.Handler.CreateConProvider)
+		r.With(a.Handler.Audit(handlers.AuditConProvider)).Post("/", a.Handler.CreateConProvider)
 
 		// Subroutes
 		r.Route("/{con_prov_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditConProvider))
+			r.Use(a.Handler.IfMatch(a.Handler.GetConProvider))
+
 			r.Get("/", a.Handler.GetConProvider)
 			r.Put("/", a.Handler.UpdateConProvider)
+			r.Patch("/", a.Handler.PatchConProvider)
 			r.Delete("/", a.Handler.DeleteConProvider)
 			r.Get("/connections", a.Handler.GetConProviderConnections)
 		})
@@ -234,14 +357,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/connections/types" resource
 	r.Route("/connections/types", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetConTypes)
 		r.Get("/count", a.Handler.CountConTypes)
-		r.Post("/", a.Handler.CreateConType)
+		r.With(a.Handler.Audit(handlers.AuditConType)).Post("/", a.Handler.CreateConType)
 
 		// Subroutes
 		r.Route("/{con_type_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditConType))
+			r.Use(a.Handler.IfMatch(a.Handler.GetConType))
+
 			r.Get("/", a.Handler.GetConType)
 			r.Put("/", a.Handler.UpdateConType)
+			r.Patch("/", a.Handler.PatchConType)
 			r.Delete("/", a.Handler.DeleteConType)
 			r.Get("/connections", a.Handler.GetConTypeConnections)
 		})
@@ -251,12 +380,18 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices", func(r chi.Router) {
 		r.Get("/", a.Handler.GetDevices)
 		r.Get("/count", a.Handler.CountDevices)
-		r.Post("/", a.Handler.CreateDevice)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDevice), a.Handler.Audit(handlers.AuditDevice)).Post("/", a.Handler.CreateDevice)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDevice)).Put("/{dev_id:[0-9]+}/sync", a.Handler.SyncDevice)
 
 		// Subroutes
 		r.Route("/{dev_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDevice))
+			r.Use(a.Handler.Audit(handlers.AuditDevice))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDevice))
+
 			r.Get("/", a.Handler.GetDevice)
 			r.Put("/", a.Handler.UpdateDevice)
+			r.Patch("/", a.Handler.PatchDevice)
 			r.Delete("/", a.Handler.DeleteDevice)
 			r.Get("/childs", a.Handler.GetDeviceChilds)
 			r.Get("/credentials", a.Handler.GetDeviceDeviceCredentials)
@@ -282,14 +417,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/devices/classes" resource
 	r.Route("/devices/classes", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetDeviceClasses)
 		r.Get("/count", a.Handler.CountDeviceClasses)
-		r.Post("/", a.Handler.CreateDeviceClass)
+		r.With(a.Handler.Audit(handlers.AuditDeviceClass)).Post("/", a.Handler.CreateDeviceClass)
 
 		// Subroutes
 		r.Route("/{class_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditDeviceClass))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceClass))
+
 			r.Get("/", a.Handler.GetDeviceClass)
 			r.Put("/", a.Handler.UpdateDeviceClass)
+			r.Patch("/", a.Handler.PatchDeviceClass)
 			r.Delete("/", a.Handler.DeleteDeviceClass)
 			r.Get("/types", a.Handler.GetDeviceClassTypes)
 		})
@@ -299,41 +440,79 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices/credentials", func(r chi.Router) {
 		r.Get("/", a.Handler.GetDeviceCredentials)
 		r.Get("/count", a.Handler.CountDeviceCredentials)
-		r.Post("/", a.Handler.CreateDeviceCredential)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDeviceCredential), a.Handler.Audit(handlers.AuditDeviceCredential)).Post("/", a.Handler.CreateDeviceCredential)
 
 		// Subroutes
 		r.Route("/{cred_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDeviceCredential))
+			r.Use(a.Handler.Audit(handlers.AuditDeviceCredential))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceCredential))
+
 			r.Get("/", a.Handler.GetDeviceCredential)
+			r.With(a.Handler.AuthorizeReveal).Get("/reveal", a.Handler.RevealDeviceCredential)
 			r.Put("/", a.Handler.UpdateDeviceCredential)
+			r.Patch("/", a.Handler.PatchDeviceCredential)
 			r.Delete("/", a.Handler.DeleteDeviceCredential)
 		})
 	})
 
 	// Routes for "/devices/domains" resource
 	r.Route("/devices/domains", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetDeviceDomains)
 		r.Get("/count", a.Handler.CountDeviceDomains)
-		r.Post("/", a.Handler.CreateDeviceDomain)
+		r.With(a.Handler.Audit(handlers.AuditDeviceDomain)).Post("/", a.Handler.CreateDeviceDomain)
 
 		// Subroutes
 		r.Route("/{dom_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDomain))
+			r.Use(a.Handler.Audit(handlers.AuditDeviceDomain))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceDomain))
+
 			r.Get("/", a.Handler.GetDeviceDomain)
 			r.Put("/", a.Handler.UpdateDeviceDomain)
+			r.Patch("/", a.Handler.PatchDeviceDomain)
 			r.Delete("/", a.Handler.DeleteDeviceDomain)
 			r.Get("/devices", a.Handler.GetDeviceDomainDevices)
 		})
 	})
 
+	// Routes for "/devices/extensions" resource
+	r.Route("/devices/extensions", func(r chi.Router) {
+		r.Get("/", a.Handler.GetDeviceExtensions)
+		r.Get("/count", a.Handler.CountDeviceExtensions)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDeviceExtension), a.Handler.Audit(handlers.AuditDeviceExtension)).Post("/", a.Handler.CreateDeviceExtension)
+
+		// Subroutes
+		r.Route("/{ext_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDeviceExtension))
+			r.Use(a.Handler.Audit(handlers.AuditDeviceExtension))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceExtension))
+
+			r.Get("/", a.Handler.GetDeviceExtension)
+			r.Put("/", a.Handler.UpdateDeviceExtension)
+			r.Patch("/", a.Handler.PatchDeviceExtension)
+			r.Delete("/", a.Handler.DeleteDeviceExtension)
+			r.Get("/device", a.Handler.GetDeviceExtensionDevice)
+		})
+	})
+
 	// Routes for "/devices/licenses" resource
 	r.Route("/devices/licenses", func(r chi.Router) {
 		r.Get("/", a.Handler.GetDeviceLicenses)
 		r.Get("/count", a.Handler.CountDeviceLicenses)
-		r.Post("/", a.Handler.CreateDeviceLicense)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDeviceLicense), a.Handler.Audit(handlers.AuditDeviceLicense)).Post("/", a.Handler.CreateDeviceLicense)
 
 		// Subroutes
 		r.Route("/{lic_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDeviceLicense))
+			r.Use(a.Handler.Audit(handlers.AuditDeviceLicense))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceLicense))
+
 			r.Get("/", a.Handler.GetDeviceLicense)
 			r.Put("/", a.Handler.UpdateDeviceLicense)
+			r.Patch("/", a.Handler.PatchDeviceLicense)
 			r.Delete("/", a.Handler.DeleteDeviceLicense)
 			r.Get("/device", a.Handler.GetDeviceLicenseDevice)
 		})
@@ -343,12 +522,17 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices/ospf_nbrs", func(r chi.Router) {
 		r.Get("/", a.Handler.GetOspfNbrs)
 		r.Get("/count", a.Handler.CountOspfNbrs)
-		r.Post("/", a.Handler.CreateOspfNbr)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeOspfNbr), a.Handler.Audit(handlers.AuditOspfNbr)).Post("/", a.Handler.CreateOspfNbr)
 
 		// Subroutes
 		r.Route("/{nbr_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeOspfNbr))
+			r.Use(a.Handler.Audit(handlers.AuditOspfNbr))
+			r.Use(a.Handler.IfMatch(a.Handler.GetOspfNbr))
+
 			r.Get("/", a.Handler.GetOspfNbr)
 			r.Put("/", a.Handler.UpdateOspfNbr)
+			r.Patch("/", a.Handler.PatchOspfNbr)
 			r.Delete("/", a.Handler.DeleteOspfNbr)
 			r.Get("/device", a.Handler.GetOspfNbrDevice)
 		})
@@ -358,28 +542,61 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices/rl_nbrs", func(r chi.Router) {
 		r.Get("/", a.Handler.GetRlNbrs)
 		r.Get("/count", a.Handler.CountRlNbrs)
-		r.Post("/", a.Handler.CreateRlNbr)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeRlNbr), a.Handler.Audit(handlers.AuditRlNbr)).Post("/", a.Handler.CreateRlNbr)
 
 		// Subroutes
 		r.Route("/{nbr_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeRlNbr))
+			r.Use(a.Handler.Audit(handlers.AuditRlNbr))
+			r.Use(a.Handler.IfMatch(a.Handler.GetRlNbr))
+
 			r.Get("/", a.Handler.GetRlNbr)
 			r.Put("/", a.Handler.UpdateRlNbr)
+			r.Patch("/", a.Handler.PatchRlNbr)
 			r.Delete("/", a.Handler.DeleteRlNbr)
 			r.Get("/device", a.Handler.GetRlNbrDevice)
 			r.Get("/entity", a.Handler.GetRlNbrEntity)
 		})
 	})
 
+	// Routes for "/devices/states" resource
+	r.Route("/devices/states", func(r chi.Router) {
+		r.Get("/", a.Handler.GetDeviceStates)
+		r.Get("/count", a.Handler.CountDeviceStates)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDeviceState), a.Handler.Audit(handlers.AuditDeviceState)).Post("/", a.Handler.CreateDeviceState)
+
+		// Subroutes
+		r.Route("/{dev_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeDeviceState))
+			r.Use(a.Handler.Audit(handlers.AuditDeviceState))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceState))
+
+			r.Get("/", a.Handler.GetDeviceState)
+			r.Put("/", a.Handler.UpdateDeviceState)
+			r.Patch("/", a.Handler.PatchDeviceState)
+			r.Delete("/", a.Handler.DeleteDeviceState)
+			r.Put("/reachability", a.Handler.ReportDeviceReachability)
+			r.Get("/device", a.Handler.GetDeviceStateDevice)
+		})
+	})
+
 	// Routes for "/devices/snmp_credentials" resource
 	r.Route("/devices/snmp_credentials", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetSnmpCredentials)
 		r.Get("/count", a.Handler.CountSnmpCredentials)
-		r.Post("/", a.Handler.CreateSnmpCredential)
+		r.With(a.Handler.Audit(handlers.AuditSnmpCredential)).Post("/", a.Handler.CreateSnmpCredential)
 
 		// Subroutes
 		r.Route("/{snmp_cred_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditSnmpCredential))
+			r.Use(a.Handler.IfMatch(a.Handler.GetSnmpCredential))
+
 			r.Get("/", a.Handler.GetSnmpCredential)
+			r.With(a.Handler.AuthorizeReveal).Get("/reveal", a.Handler.RevealSnmpCredential)
 			r.Put("/", a.Handler.UpdateSnmpCredential)
+			r.Patch("/", a.Handler.PatchSnmpCredential)
 			r.Delete("/", a.Handler.DeleteSnmpCredential)
 			r.Get("/main_devices", a.Handler.GetSnmpCredentialsMainDevices)
 			r.Get("/ro_devices", a.Handler.GetSnmpCredentialsRoDevices)
@@ -388,14 +605,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/devices/types" resource
 	r.Route("/devices/types", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetDeviceTypes)
 		r.Get("/count", a.Handler.CountDeviceTypes)
-		r.Post("/", a.Handler.CreateDeviceType)
+		r.With(a.Handler.Audit(handlers.AuditDeviceType)).Post("/", a.Handler.CreateDeviceType)
 
 		// Subroutes
 		r.Route("/{sys_id:[\\w-\\.]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditDeviceType))
+			r.Use(a.Handler.IfMatch(a.Handler.GetDeviceType))
+
 			r.Get("/", a.Handler.GetDeviceType)
 			r.Put("/", a.Handler.UpdateDeviceType)
+			r.Patch("/", a.Handler.PatchDeviceType)
 			r.Delete("/", a.Handler.DeleteDeviceType)
 			r.Get("/class", a.Handler.GetDeviceTypeClass)
 			r.Get("/devices", a.Handler.GetDeviceTypeDevices)
@@ -406,12 +629,18 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices/vlans", func(r chi.Router) {
 		r.Get("/", a.Handler.GetVlans)
 		r.Get("/count", a.Handler.CountVlans)
-		r.Post("/", a.Handler.CreateVlan)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeVlan), a.Handler.Audit(handlers.AuditVlan)).Post("/", a.Handler.CreateVlan)
+		r.Post("/bulk", a.Handler.BulkVlans)
 
 		// Subroutes
 		r.Route("/{v_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeVlan))
+			r.Use(a.Handler.Audit(handlers.AuditVlan))
+			r.Use(a.Handler.IfMatch(a.Handler.GetVlan))
+
 			r.Get("/", a.Handler.GetVlan)
 			r.Put("/", a.Handler.UpdateVlan)
+			r.Patch("/", a.Handler.PatchVlan)
 			r.Delete("/", a.Handler.DeleteVlan)
 			r.Get("/device", a.Handler.GetVlanDevice)
 		})
@@ -421,12 +650,18 @@ func (a *App) initializeRoutes() {
 	r.Route("/devices/xconnects", func(r chi.Router) {
 		r.Get("/", a.Handler.GetXconnects)
 		r.Get("/count", a.Handler.CountXconnects)
-		r.Post("/", a.Handler.CreateXconnect)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeXconnect), a.Handler.Audit(handlers.AuditXconnect)).Post("/", a.Handler.CreateXconnect)
+		r.Post("/bulk", a.Handler.BulkXconnects)
 
 		// Subroutes
 		r.Route("/{xc_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeXconnect))
+			r.Use(a.Handler.Audit(handlers.AuditXconnect))
+			r.Use(a.Handler.IfMatch(a.Handler.GetXconnect))
+
 			r.Get("/", a.Handler.GetXconnect)
 			r.Put("/", a.Handler.UpdateXconnect)
+			r.Patch("/", a.Handler.PatchXconnect)
 			r.Delete("/", a.Handler.DeleteXconnect)
 			r.Get("/device", a.Handler.GetXconnectDevice)
 			r.Get("/peer_device", a.Handler.GetXconnectPeerDevice)
@@ -438,12 +673,18 @@ func (a *App) initializeRoutes() {
 	r.Route("/entities", func(r chi.Router) {
 		r.Get("/", a.Handler.GetEntities)
 		r.Get("/count", a.Handler.CountEntities)
-		r.Post("/", a.Handler.CreateEntity)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeEntity), a.Handler.Audit(handlers.AuditEntity)).Post("/", a.Handler.CreateEntity)
+		r.Post("/bulk", a.Handler.BulkEntities)
 
 		// Subroutes
 		r.Route("/{ent_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeEntity))
+			r.Use(a.Handler.Audit(handlers.AuditEntity))
+			r.Use(a.Handler.IfMatch(a.Handler.GetEntity))
+
 			r.Get("/", a.Handler.GetEntity)
 			r.Put("/", a.Handler.UpdateEntity)
+			r.Patch("/", a.Handler.PatchEntity)
 			r.Delete("/", a.Handler.DeleteEntity)
 			r.Get("/childs", a.Handler.GetEntityChilds)
 			r.Get("/device", a.Handler.GetEntityDevice)
@@ -456,28 +697,61 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/entities/custom_entities" resource
 	r.Route("/entities/custom_entities", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetCustomEntities)
 		r.Get("/count", a.Handler.CountCustomEntities)
-		r.Post("/", a.Handler.CreateCustomEntity)
+		r.With(a.Handler.Audit(handlers.AuditCustomEntity)).Post("/", a.Handler.CreateCustomEntity)
 
 		// Subroutes
 		r.Route("/{cent_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditCustomEntity))
+			r.Use(a.Handler.IfMatch(a.Handler.GetCustomEntity))
+
 			r.Get("/", a.Handler.GetCustomEntity)
 			r.Put("/", a.Handler.UpdateCustomEntity)
+			r.Patch("/", a.Handler.PatchCustomEntity)
 			r.Delete("/", a.Handler.DeleteCustomEntity)
 		})
 	})
 
+	// Routes for "/entities/phy_indexes" resource
+	r.Route("/entities/phy_indexes", func(r chi.Router) {
+		r.Get("/", a.Handler.GetEntityPhyIndexes)
+		r.Get("/count", a.Handler.CountEntityPhyIndexes)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeDevice)).Get("/lookup/{dev_id:[0-9]+}/{phy_index:[0-9]+}", a.Handler.LookupEntityPhyIndex)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeEntityPhyIndex), a.Handler.Audit(handlers.AuditEntityPhyIndex)).Post("/", a.Handler.CreateEntityPhyIndex)
+
+		// Subroutes
+		r.Route("/{ei_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeEntityPhyIndex))
+			r.Use(a.Handler.Audit(handlers.AuditEntityPhyIndex))
+			r.Use(a.Handler.IfMatch(a.Handler.GetEntityPhyIndex))
+
+			r.Get("/", a.Handler.GetEntityPhyIndex)
+			r.Put("/", a.Handler.UpdateEntityPhyIndex)
+			r.Patch("/", a.Handler.PatchEntityPhyIndex)
+			r.Delete("/", a.Handler.DeleteEntityPhyIndex)
+			r.Get("/entity", a.Handler.GetEntityPhyIndexEntity)
+		})
+	})
+
 	// Routes for "/interfaces" resource
 	r.Route("/interfaces", func(r chi.Router) {
 		r.Get("/", a.Handler.GetInterfaces)
 		r.Get("/count", a.Handler.CountInterfaces)
-		r.Post("/", a.Handler.CreateInterface)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeInterface), a.Handler.Audit(handlers.AuditInterface)).Post("/", a.Handler.CreateInterface)
+		r.Post("/bulk", a.Handler.BulkInterfaces)
 
 		// Subroutes
 		r.Route("/{if_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeInterface))
+			r.Use(a.Handler.Audit(handlers.AuditInterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetInterface))
+
 			r.Get("/", a.Handler.GetInterface)
 			r.Put("/", a.Handler.UpdateInterface)
+			r.Patch("/", a.Handler.PatchInterface)
 			r.Delete("/", a.Handler.DeleteInterface)
 			r.Get("/bw_stats", a.Handler.GetInterfaceIntBwStats)
 			r.Get("/childs", a.Handler.GetInterfaceChilds)
@@ -485,9 +759,11 @@ func (a *App) initializeRoutes() {
 			r.Get("/device", a.Handler.GetInterfaceDevice)
 			r.Get("/entity", a.Handler.GetInterfaceEntity)
 			r.Get("/otn_if", a.Handler.GetInterfaceOtnIf)
+			r.Get("/otn_clients", a.Handler.GetInterfaceOtnClients)
 			r.Get("/parent", a.Handler.GetInterfaceParent)
 			r.Get("/related_higher", a.Handler.GetInterfaceInterfaceRelationsLowerFor)
 			r.Get("/related_lower", a.Handler.GetInterfaceInterfaceRelationsHigherFor)
+			r.Get("/stack", a.Handler.GetInterfaceStack)
 			r.Get("/subinterfaces", a.Handler.GetInterfaceSubinterfaces)
 			r.Get("/vlans", a.Handler.GetInterfaceVlans)
 			r.Get("/xconnects", a.Handler.GetInterfaceXconnects)
@@ -498,27 +774,79 @@ func (a *App) initializeRoutes() {
 	r.Route("/interfaces/bw_stats", func(r chi.Router) {
 		r.Get("/", a.Handler.GetIntBwStats)
 		r.Get("/count", a.Handler.CountIntBwStats)
-		r.Post("/", a.Handler.CreateIntBwStat)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeIntBwStat), a.Handler.Audit(handlers.AuditIntBwStat)).Post("/", a.Handler.CreateIntBwStat)
 
 		// Subroutes
 		r.Route("/{bw_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeIntBwStat))
+			r.Use(a.Handler.Audit(handlers.AuditIntBwStat))
+			r.Use(a.Handler.IfMatch(a.Handler.GetIntBwStat))
+
 			r.Get("/", a.Handler.GetIntBwStat)
 			r.Put("/", a.Handler.UpdateIntBwStat)
+			r.Patch("/", a.Handler.PatchIntBwStat)
 			r.Delete("/", a.Handler.DeleteIntBwStat)
 			r.Get("/interface", a.Handler.GetIntBwStatInterface)
 		})
 	})
 
+	// Routes for "/interfaces/otn" resource
+	r.Route("/interfaces/otn", func(r chi.Router) {
+		r.Get("/", a.Handler.GetOtnMappings)
+		r.Get("/count", a.Handler.CountOtnMappings)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeOtnMapping), a.Handler.Audit(handlers.AuditOtnMapping)).Post("/", a.Handler.CreateOtnMapping)
+
+		// Subroutes
+		r.Route("/{if_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeOtnMapping))
+			r.Use(a.Handler.Audit(handlers.AuditInterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetOtnMapping))
+
+			r.Get("/", a.Handler.GetOtnMapping)
+			r.Put("/", a.Handler.UpdateOtnMapping)
+			r.Patch("/", a.Handler.PatchOtnMapping)
+			r.Delete("/", a.Handler.DeleteOtnMapping)
+		})
+	})
+
+	// Routes for "/interfaces/relations" resource
+	r.Route("/interfaces/relations", func(r chi.Router) {
+		r.Get("/", a.Handler.GetInterfaceRelations)
+		r.Get("/count", a.Handler.CountInterfaceRelations)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeIfRelation), a.Handler.Audit(handlers.AuditIfRelation)).Post("/", a.Handler.CreateInterfaceRelation)
+
+		// Subroutes
+		r.Route("/{ir_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeIfRelation))
+			r.Use(a.Handler.Audit(handlers.AuditIfRelation))
+			r.Use(a.Handler.IfMatch(a.Handler.GetInterfaceRelation))
+
+			r.Get("/", a.Handler.GetInterfaceRelation)
+			r.Put("/", a.Handler.UpdateInterfaceRelation)
+			r.Patch("/", a.Handler.PatchInterfaceRelation)
+			r.Delete("/", a.Handler.DeleteInterfaceRelation)
+			r.Get("/interface", a.Handler.GetInterfaceRelationInterface)
+			r.Get("/interface_up", a.Handler.GetInterfaceRelationInterfaceUp)
+			r.Get("/interface_down", a.Handler.GetInterfaceRelationInterfaceDown)
+		})
+	})
+
 	// Routes for "/interfaces/subinterfaces" resource
 	r.Route("/interfaces/subinterfaces", func(r chi.Router) {
 		r.Get("/", a.Handler.GetSubinterfaces)
 		r.Get("/count", a.Handler.CountSubinterfaces)
-		r.Post("/", a.Handler.CreateSubinterface)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeSubinterface), a.Handler.Audit(handlers.AuditSubinterface)).Post("/", a.Handler.CreateSubinterface)
+		r.Post("/bulk", a.Handler.BulkSubinterfaces)
 
 		// Subroutes
 		r.Route("/{sif_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeSubinterface))
+			r.Use(a.Handler.Audit(handlers.AuditSubinterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetSubinterface))
+
 			r.Get("/", a.Handler.GetSubinterface)
 			r.Put("/", a.Handler.UpdateSubinterface)
+			r.Patch("/", a.Handler.PatchSubinterface)
 			r.Delete("/", a.Handler.DeleteSubinterface)
 			r.Get("/interface", a.Handler.GetSubinterfaceInterface)
 		})
@@ -528,12 +856,18 @@ func (a *App) initializeRoutes() {
 	r.Route("/ip_interfaces", func(r chi.Router) {
 		r.Get("/", a.Handler.GetIpInterfaces)
 		r.Get("/count", a.Handler.CountIpInterfaces)
-		r.Post("/", a.Handler.CreateIpInterface)
+		r.With(a.Handler.AuthorizeDomain(handlers.ScopeIpInterface), a.Handler.Audit(handlers.AuditIpInterface)).Post("/", a.Handler.CreateIpInterface)
+		r.Post("/bulk", a.Handler.BulkIpInterfaces)
 
 		// Subroutes
 		r.Route("/{ip_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.AuthorizeDomain(handlers.ScopeIpInterface))
+			r.Use(a.Handler.Audit(handlers.AuditIpInterface))
+			r.Use(a.Handler.IfMatch(a.Handler.GetIpInterface))
+
 			r.Get("/", a.Handler.GetIpInterface)
 			r.Put("/", a.Handler.UpdateIpInterface)
+			r.Patch("/", a.Handler.PatchIpInterface)
 			r.Delete("/", a.Handler.DeleteIpInterface)
 			r.Get("/device", a.Handler.GetIpInterfaceDevice)
 		})
@@ -541,14 +875,20 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/sites" resource
 	r.Route("/sites", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetSites)
 		r.Get("/count", a.Handler.CountSites)
-		r.Post("/", a.Handler.CreateSite)
+		r.With(a.Handler.Audit(handlers.AuditSite)).Post("/", a.Handler.CreateSite)
 
 		// Subroutes
 		r.Route("/{site_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditSite))
+			r.Use(a.Handler.IfMatch(a.Handler.GetSite))
+
 			r.Get("/", a.Handler.GetSite)
 			r.Put("/", a.Handler.UpdateSite)
+			r.Patch("/", a.Handler.PatchSite)
 			r.Delete("/", a.Handler.DeleteSite)
 			r.Get("/country", a.Handler.GetSiteConCountry)
 			r.Get("/connections", a.Handler.GetSiteConnections)
@@ -558,45 +898,86 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/sites/countries" resource
 	r.Route("/sites/countries", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetCountries)
 		r.Get("/count", a.Handler.CountCountries)
-		r.Post("/", a.Handler.CreateCountry)
+		r.With(a.Handler.Audit(handlers.AuditCountry)).Post("/", a.Handler.CreateCountry)
 
 		// Subroutes
 		r.Route("/{country_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditCountry))
+			r.Use(a.Handler.IfMatch(a.Handler.GetCountry))
+
 			r.Get("/", a.Handler.GetCountry)
 			r.Put("/", a.Handler.UpdateCountry)
+			r.Patch("/", a.Handler.PatchCountry)
 			r.Delete("/", a.Handler.DeleteCountry)
 			r.Get("/sites", a.Handler.GetCountrySites)
 		})
 	})
 
+	// Routes for "/audit" resource
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdmin)
+
+		r.Get("/", a.Handler.GetAudit)
+		r.Get("/count", a.Handler.CountAudit)
+		r.Get("/{audit_id:[0-9]+}", a.Handler.GetAuditRecord)
+	})
+
 	// Routes for "/users" resource
 	r.Route("/users", func(r chi.Router) {
 		r.Get("/", a.Handler.GetUsers)
 		r.Get("/count", a.Handler.CountUsers)
-		r.Post("/", a.Handler.CreateUser)
+		r.With(a.Handler.AuthorizeAdminWrite, a.Handler.Audit(handlers.AuditUser)).Post("/", a.Handler.CreateUser)
 
 		// Subroutes
 		r.Route("/{username:\\w+}", func(r chi.Router) {
 			r.Get("/", a.Handler.GetUser)
-			r.Put("/", a.Handler.UpdateUser)
-			r.Delete("/", a.Handler.DeleteUser)
+			r.With(a.Handler.AuthorizeAdminWrite, a.Handler.Audit(handlers.AuditUser), a.Handler.IfMatch(a.Handler.GetUser)).Put("/", a.Handler.UpdateUser)
+			r.With(a.Handler.AuthorizeAdminWrite, a.Handler.Audit(handlers.AuditUser), a.Handler.IfMatch(a.Handler.GetUser)).Patch("/", a.Handler.PatchUser)
+			r.With(a.Handler.AuthorizeAdminWrite, a.Handler.Audit(handlers.AuditUser), a.Handler.IfMatch(a.Handler.GetUser)).Delete("/", a.Handler.DeleteUser)
 			r.Get("/authzs", a.Handler.GetUserUserAuthzs)
 			r.Get("/graphs", a.Handler.GetUserUserGraphs)
+
+			// Routes for "/users/{username}/tokens" resource
+			r.Route("/tokens", func(r chi.Router) {
+				r.Use(a.Handler.AuthorizeSelfOrAdmin)
+
+				r.Get("/", a.Handler.GetUserTokens)
+				r.With(a.Handler.Audit(handlers.AuditUserToken)).Post("/", a.Handler.CreateUserToken)
+
+				// Subroutes
+				r.Route("/{token_id:[0-9]+}", func(r chi.Router) {
+					r.Use(a.Handler.Audit(handlers.AuditUserToken))
+					r.Use(a.Handler.IfMatch(a.Handler.GetUserToken))
+
+					r.Get("/", a.Handler.GetUserToken)
+					r.Put("/", a.Handler.UpdateUserToken)
+					r.Patch("/", a.Handler.PatchUserToken)
+					r.Delete("/", a.Handler.DeleteUserToken)
+				})
+			})
 		})
 	})
 
 	// Routes for "/users/authzs" resource
 	r.Route("/users/authzs", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeAdminWrite)
+
 		r.Get("/", a.Handler.GetUserAuthzs)
 		r.Get("/count", a.Handler.CountUserAuthzs)
-		r.Post("/", a.Handler.CreateUserAuthz)
+		r.With(a.Handler.Audit(handlers.AuditUserAuthz)).Post("/", a.Handler.CreateUserAuthz)
 
 		// Subroutes
 		r.Route("/{username:\\w+}/{dom_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditUserAuthz))
+			r.Use(a.Handler.IfMatch(a.Handler.GetUserAuthz))
+
 			r.Get("/", a.Handler.GetUserAuthz)
 			r.Put("/", a.Handler.UpdateUserAuthz)
+			r.Patch("/", a.Handler.PatchUserAuthz)
 			r.Delete("/", a.Handler.DeleteUserAuthz)
 			r.Get("/device_domain", a.Handler.GetUserAuthzDeviceDomain)
 		})
@@ -604,35 +985,23 @@ func (a *App) initializeRoutes() {
 
 	// Routes for "/users/graphs" resource
 	r.Route("/users/graphs", func(r chi.Router) {
+		r.Use(a.Handler.AuthorizeWrite)
+
 		r.Get("/", a.Handler.GetUserGraphs)
 		r.Get("/count", a.Handler.CountUserGraphs)
-		r.Post("/", a.Handler.CreateUserGraph)
+		r.With(a.Handler.Audit(handlers.AuditUserGraph)).Post("/", a.Handler.CreateUserGraph)
 
 		// Subroutes
 		r.Route("/{graph_id:[0-9]+}", func(r chi.Router) {
+			r.Use(a.Handler.Audit(handlers.AuditUserGraph))
+			r.Use(a.Handler.IfMatch(a.Handler.GetUserGraph))
+
 			r.Get("/", a.Handler.GetUserGraph)
 			r.Put("/", a.Handler.UpdateUserGraph)
+			r.Patch("/", a.Handler.PatchUserGraph)
 			r.Delete("/", a.Handler.DeleteUserGraph)
 		})
 	})
-
-	// Swagger
-	r.Route("/swagger", func(r chi.Router) {
-		r.Get("/*", httpSwagger.Handler(
-			httpSwagger.DocExpansion("none"),
-		))
-	})
-
-	// Custom 404 handler
-	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
-		handlers.RespondError(w, r, http.StatusNotFound, "Route does not exist")
-	})
-
-	// Custom 405 handler
-	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-		handlers.RespondError(w, r, http.StatusMethodNotAllowed, "Method is not valid")
-	})
-
 }
 
 func (a *App) Run() {
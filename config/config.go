@@ -8,14 +8,25 @@ import (
 
 // API configuration sruct
 type Configuration struct {
-	DbURL     string `env:"GODEVMANAPI_DBURL"`
-	ApiListen string `env:"GODEVMANAPI_LISTEN"`
-	Salt      string `env:"GODEVMANAPI_SALT"`
+	DbURL       string `env:"GODEVMANAPI_DBURL"`
+	ApiListen   string `env:"GODEVMANAPI_LISTEN"`
+	Salt        string `env:"GODEVMANAPI_SALT"`
+	OldSalt     string `env:"GODEVMANAPI_OLD_SALT"`
+	TokenKey    string `env:"GODEVMANAPI_TOKEN_KEY"`
+	PublicInfo  bool   `env:"GODEVMANAPI_PUBLIC_INFO"`
+	WriteLevel  int    `env:"GODEVMANAPI_WRITE_LEVEL"`
+	AdminLevel  int    `env:"GODEVMANAPI_ADMIN_LEVEL"`
+	RevealLevel int    `env:"GODEVMANAPI_REVEAL_LEVEL"`
 }
 
 // Fills Configuration struct. Prefers environment variables
 func GetConfig() (*Configuration, error) {
-	conf := new(Configuration)
+	// Defaults
+	conf := &Configuration{
+		WriteLevel:  2,
+		AdminLevel:  3,
+		RevealLevel: 3,
+	}
 
 	f := "/usr/local/etc/godevmanapi.conf"
 	if os.Getenv("GODEVMAN_TESTDB") != "" {
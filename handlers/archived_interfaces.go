@@ -90,17 +90,40 @@ func (r *archivedInterface) updateParams() godevmandb.UpdateArchivedInterfacePar
 
 // Count ArchivedInterfaces
 // @Summary Count archived_interfaces
-// @Description Count number of archived interfaces
+// @Description Count number of archived interfaces matching filters
 // @Tags archived
 // @ID count-archived_interfaces
+// @Param ifindex_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param otn_if_id_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param cisco_opt_power_index_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param hostname_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param host_ip4_f query string false "ip or containing net in CIDR notation"
+// @Param host_ip6_f query string false "ip or containing net in CIDR notation"
+// @Param manufacturer_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param model_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param type_enum_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param mac_f query string false "SQL '=' operator value (MAC address)"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/interfaces/count [GET]
 func (h *Handler) CountArchivedInterfaces(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountArchivedInterfaces(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &archivedInterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -109,6 +132,31 @@ func (h *Handler) CountArchivedInterfaces(w http.ResponseWriter, r *http.Request
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of archived interfaces
+var archivedInterfacesList = listSpec{
+	table: "archived_interfaces",
+	keys:  []string{"ifa_id"},
+	model: archivedInterface{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"ifindex_f", "ifindex", filterLikeNull},
+		{"otn_if_id_f", "otn_if_id", filterLikeNull},
+		{"cisco_opt_power_index_f", "cisco_opt_power_index", filterILikeNull},
+		{"hostname_f", "hostname", filterILikeEmpty},
+		{"host_ip4_f", "host_ip4", filterInet},
+		{"host_ip6_f", "host_ip6", filterInet},
+		{"manufacturer_f", "manufacturer", filterILikeEmpty},
+		{"model_f", "model", filterILikeEmpty},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"alias_f", "alias", filterILikeNull},
+		{"type_enum_f", "type_enum", filterLikeNull},
+		{"mac_f", "mac", filterMacaddr},
+	},
+}
+
 // List archived_interfaces
 // @Summary List archived_interfaces
 // @Description List archived interfaces info
@@ -127,95 +175,33 @@ func (h *Handler) CountArchivedInterfaces(w http.ResponseWriter, r *http.Request
 // @Param type_enum_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param mac_f query string false "SQL '=' operator value (MAC address)"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} archivedInterface
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/interfaces [GET]
 func (h *Handler) GetArchivedInterfaces(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetArchivedInterfacesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("ifindex_f"); v != "" {
-		p.IfindexF = &v
-	}
-
-	if v := r.FormValue("otn_if_id_f"); v != "" {
-		p.OtnIfIDF = &v
-	}
-
-	if v := r.FormValue("cisco_opt_power_index_f"); v != "" {
-		p.CiscoOptPowerIndexF = &v
-	}
-
-	if v := r.FormValue("hostname_f"); v != "" {
-		p.HostnameF = v
-	}
-
-	p.HostIp4F = strToPgInet(nil)
-	if v := r.FormValue("host_ip4_f"); v != "" {
-		p.HostIp4F = strToPgInet(&v)
-	}
-
-	p.HostIp6F = strToPgInet(nil)
-	if v := r.FormValue("host_ip6_f"); v != "" {
-		p.HostIp6F = strToPgInet(&v)
-	}
-
-	if v := r.FormValue("manufacturer_f"); v != "" {
-		p.ManufacturerF = v
-	}
-
-	if v := r.FormValue("model_f"); v != "" {
-		p.ModelF = v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("alias_f"); v != "" {
-		p.AliasF = &v
-	}
-
-	if v := r.FormValue("type_enum_f"); v != "" {
-		p.TypeEnumF = &v
-	}
-
-	p.MacF = strToPgMacaddr(nil)
-	if v := r.FormValue("mac_f"); v != "" {
-		p.MacF = strToPgMacaddr(&v)
+	// Query DB
+	l, err := parseListRequest(r, &archivedInterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetArchivedInterfaces(h.ctx, p)
+	res, page, err := listRows[godevmandb.ArchivedInterface](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -228,7 +214,7 @@ func (h *Handler) GetArchivedInterfaces(w http.ResponseWriter, r *http.Request)
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get ArchivedInterface
@@ -237,7 +223,10 @@ func (h *Handler) GetArchivedInterfaces(w http.ResponseWriter, r *http.Request)
 // @Tags archived
 // @ID get-archived_interface
 // @Param ifa_id path string true "ifa_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} archivedInterface
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid ifa_id"
 // @Failure 404 {object} StatusResponse "Archived interface not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -312,10 +301,13 @@ func (h *Handler) CreateArchivedInterface(w http.ResponseWriter, r *http.Request
 // @ID update-archived_interface
 // @Param ifa_id path string true "ifa_id"
 // @Param Body body archivedInterface true "JSON object of archivedInterface.<br />Ignored fields:<ul><li>ifa_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} archivedInterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/interfaces/{ifa_id} [PUT]
 func (h *Handler) UpdateArchivedInterface(w http.ResponseWriter, r *http.Request) {
@@ -351,16 +343,39 @@ func (h *Handler) UpdateArchivedInterface(w http.ResponseWriter, r *http.Request
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch ArchivedInterface
+// @Summary Patch archived_interface
+// @Description Partially update archived_interface with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags archived
+// @ID patch-archived_interface
+// @Param ifa_id path string true "ifa_id"
+// @Param Body body archivedInterface true "JSON merge patch of archivedInterface.<br />Ignored fields:<ul><li>ifa_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} archivedInterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /archived/interfaces/{ifa_id} [PATCH]
+func (h *Handler) PatchArchivedInterface(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetArchivedInterface, h.UpdateArchivedInterface)
+}
+
 // Delete ArchivedInterface
 // @Summary Delete archived_interface
 // @Description Delete archived interface
 // @Tags archived
 // @ID delete-archived_interface
 // @Param ifa_id path string true "ifa_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid ifa_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/interfaces/{ifa_id} [DELETE]
 func (h *Handler) DeleteArchivedInterface(w http.ResponseWriter, r *http.Request) {
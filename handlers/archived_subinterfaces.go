@@ -78,17 +78,38 @@ func (r *archivedSubinterface) updateParams() godevmandb.UpdateArchivedSubinterf
 
 // Count ArchivedSubinterfaces
 // @Summary Count archived_subinterfaces
-// @Description Count number of archived subinterfaces
+// @Description Count number of archived subinterfaces matching filters
 // @Tags archived
 // @ID count-archived_subinterfaces
+// @Param ifindex_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param parent_descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param type_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param mac_f query string false "SQL '=' operator value (MAC address)"
+// @Param hostname_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param host_ip4_f query string false "ip or containing net in CIDR notation"
+// @Param host_ip6_f query string false "ip or containing net in CIDR notation"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/subinterfaces/count [GET]
 func (h *Handler) CountArchivedSubinterfaces(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountArchivedSubinterfaces(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &archivedSubinterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -97,6 +118,29 @@ func (h *Handler) CountArchivedSubinterfaces(w http.ResponseWriter, r *http.Requ
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of archived subinterfaces
+var archivedSubinterfacesList = listSpec{
+	table: "archived_subinterfaces",
+	keys:  []string{"sifa_id"},
+	model: archivedSubinterface{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"ifindex_f", "ifindex", filterLikeNull},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"parent_descr_f", "parent_descr", filterILikeNull},
+		{"alias_f", "alias", filterILikeNull},
+		{"type_f", "type", filterILikeNull},
+		{"mac_f", "mac", filterMacaddr},
+		{"hostname_f", "hostname", filterILikeEmpty},
+		{"host_ip4_f", "host_ip4", filterInet},
+		{"host_ip6_f", "host_ip6", filterInet},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List archived_subinterfaces
 // @Summary List archived_subinterfaces
 // @Description List archived subinterfaces info
@@ -113,87 +157,33 @@ func (h *Handler) CountArchivedSubinterfaces(w http.ResponseWriter, r *http.Requ
 // @Param host_ip6_f query string false "ip or containing net in CIDR notation"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} archivedSubinterface
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/subinterfaces [GET]
 func (h *Handler) GetArchivedSubinterfaces(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetArchivedSubinterfacesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("ifindex_f"); v != "" {
-		p.IfindexF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("parent_descr_f"); v != "" {
-		p.ParentDescrF = &v
-	}
-
-	if v := r.FormValue("alias_f"); v != "" {
-		p.AliasF = &v
-	}
-
-	if v := r.FormValue("type_f"); v != "" {
-		p.TypeF = &v
-	}
-
-	p.MacF = strToPgMacaddr(nil)
-	if v := r.FormValue("mac_f"); v != "" {
-		p.MacF = strToPgMacaddr(&v)
-	}
-
-	if v := r.FormValue("hostname_f"); v != "" {
-		p.HostnameF = v
-	}
-
-	p.HostIp4F = strToPgInet(nil)
-	if v := r.FormValue("host_ip4_f"); v != "" {
-		p.HostIp4F = strToPgInet(&v)
-	}
-
-	p.HostIp6F = strToPgInet(nil)
-	if v := r.FormValue("host_ip6_f"); v != "" {
-		p.HostIp6F = strToPgInet(&v)
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &archivedSubinterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetArchivedSubinterfaces(h.ctx, p)
+	res, page, err := listRows[godevmandb.ArchivedSubinterface](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -206,7 +196,7 @@ func (h *Handler) GetArchivedSubinterfaces(w http.ResponseWriter, r *http.Reques
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get ArchivedSubinterface
@@ -215,7 +205,10 @@ func (h *Handler) GetArchivedSubinterfaces(w http.ResponseWriter, r *http.Reques
 // @Tags archived
 // @ID get-archived_subinterface
 // @Param sifa_id path string true "sifa_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} archivedSubinterface
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid sifa_id"
 // @Failure 404 {object} StatusResponse "Archived subinterface not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -290,10 +283,13 @@ func (h *Handler) CreateArchivedSubinterface(w http.ResponseWriter, r *http.Requ
 // @ID update-archived_subinterface
 // @Param sifa_id path string true "sifa_id"
 // @Param Body body archivedSubinterface true "JSON object of archivedSubinterface.<br />Ignored fields:<ul><li>sifa_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} archivedSubinterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/subinterfaces/{sifa_id} [PUT]
 func (h *Handler) UpdateArchivedSubinterface(w http.ResponseWriter, r *http.Request) {
@@ -329,16 +325,39 @@ func (h *Handler) UpdateArchivedSubinterface(w http.ResponseWriter, r *http.Requ
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch ArchivedSubinterface
+// @Summary Patch archived_subinterface
+// @Description Partially update archived_subinterface with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags archived
+// @ID patch-archived_subinterface
+// @Param sifa_id path string true "sifa_id"
+// @Param Body body archivedSubinterface true "JSON merge patch of archivedSubinterface.<br />Ignored fields:<ul><li>sifa_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} archivedSubinterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /archived/subinterfaces/{sifa_id} [PATCH]
+func (h *Handler) PatchArchivedSubinterface(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetArchivedSubinterface, h.UpdateArchivedSubinterface)
+}
+
 // Delete ArchivedSubinterface
 // @Summary Delete archived_subinterface
 // @Description Delete archived subinterface
 // @Tags archived
 // @ID delete-archived_subinterface
 // @Param sifa_id path string true "sifa_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid sifa_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /archived/subinterfaces/{sifa_id} [DELETE]
 func (h *Handler) DeleteArchivedSubinterface(w http.ResponseWriter, r *http.Request) {
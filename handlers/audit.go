@@ -0,0 +1,494 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+)
+
+// Error of missing row of update or delete
+var errRowNotFound = errors.New("Row not found")
+
+// Columns which values are masked in audit log
+var auditSecrets = map[string]bool{
+	"enc_secret": true,
+	"auth_pass":  true,
+	"priv_pass":  true,
+	"token_hash": true,
+}
+
+// Audit scope of resource.
+// Keys are primary key columns of table. URL parameters have same names as keys.
+// Field is JSON field of created row ID in response if it differs from key.
+// If payload is set, POST request changes existing row identified by keys in request payload
+// and it is recorded as update
+type AuditScope struct {
+	table   string
+	keys    []string
+	field   string
+	payload bool
+}
+
+// Audit scopes of resources
+var (
+	AuditArchivedInterface    = AuditScope{table: "archived_interfaces", keys: []string{"ifa_id"}}
+	AuditArchivedSubinterface = AuditScope{table: "archived_subinterfaces", keys: []string{"sifa_id"}}
+	AuditConCapacity          = AuditScope{table: "con_capacities", keys: []string{"con_cap_id"}}
+	AuditConClass             = AuditScope{table: "con_classes", keys: []string{"con_class_id"}}
+	AuditConProvider          = AuditScope{table: "con_providers", keys: []string{"con_prov_id"}}
+	AuditConType              = AuditScope{table: "con_types", keys: []string{"con_type_id"}}
+	AuditConnection           = AuditScope{table: "connections", keys: []string{"con_id"}}
+	AuditCountry              = AuditScope{table: "countries", keys: []string{"country_id"}}
+	AuditCredential           = AuditScope{table: "credentials", keys: []string{"cred_id"}}
+	AuditCustomEntity         = AuditScope{table: "custom_entities", keys: []string{"cent_id"}}
+	AuditDevice               = AuditScope{table: "devices", keys: []string{"dev_id"}}
+	AuditDeviceClass          = AuditScope{table: "device_classes", keys: []string{"class_id"}}
+	AuditDeviceCredential     = AuditScope{table: "device_credentials", keys: []string{"cred_id"}}
+	AuditDeviceDomain         = AuditScope{table: "device_domains", keys: []string{"dom_id"}}
+	AuditDeviceExtension      = AuditScope{table: "device_extensions", keys: []string{"ext_id"}}
+	AuditDeviceLicense        = AuditScope{table: "device_licenses", keys: []string{"lic_id"}}
+	AuditDeviceState          = AuditScope{table: "device_states", keys: []string{"dev_id"}}
+	AuditDeviceType           = AuditScope{table: "device_types", keys: []string{"sys_id"}}
+	AuditEntity               = AuditScope{table: "entities", keys: []string{"ent_id"}}
+	AuditEntityPhyIndex       = AuditScope{table: "entity_phy_indexes", keys: []string{"ei_id"}}
+	AuditIntBwStat            = AuditScope{table: "int_bw_stats", keys: []string{"bw_id"}}
+	AuditInterface            = AuditScope{table: "interfaces", keys: []string{"if_id"}}
+	AuditIfRelation           = AuditScope{table: "interface_relations", keys: []string{"ir_id"}}
+	AuditIpInterface          = AuditScope{table: "ip_interfaces", keys: []string{"ip_id"}}
+	AuditOtnMapping           = AuditScope{table: "interfaces", keys: []string{"if_id"}, payload: true}
+	AuditOspfNbr              = AuditScope{table: "ospf_nbrs", keys: []string{"nbr_id"}}
+	AuditRlNbr                = AuditScope{table: "rl_nbrs", keys: []string{"nbr_id"}}
+	AuditSite                 = AuditScope{table: "sites", keys: []string{"site_id"}}
+	AuditSnmpCredential       = AuditScope{table: "snmp_credentials", keys: []string{"snmp_cred_id"}, field: "snmp_snmp_cred_id"}
+	AuditSubinterface         = AuditScope{table: "subinterfaces", keys: []string{"sif_id"}}
+	AuditUser                 = AuditScope{table: "users", keys: []string{"username"}}
+	AuditUserAuthz            = AuditScope{table: "user_authzs", keys: []string{"username", "dom_id"}}
+	AuditUserGraph            = AuditScope{table: "user_graphs", keys: []string{"graph_id"}}
+	AuditUserToken            = AuditScope{table: "user_tokens", keys: []string{"token_id"}}
+	AuditVar                  = AuditScope{table: "vars", keys: []string{"descr"}}
+	AuditVlan                 = AuditScope{table: "vlans", keys: []string{"v_id"}}
+	AuditXconnect             = AuditScope{table: "xconnects", keys: []string{"xc_id"}}
+)
+
+// Primary key values from URL parameters. Returns nil if any of them is missing
+func (s AuditScope) pathIDs(r *http.Request) []string {
+	ids := make([]string, 0, len(s.keys))
+	for _, k := range s.keys {
+		v := chi.URLParam(r, k)
+		if v == "" {
+			return nil
+		}
+		ids = append(ids, v)
+	}
+
+	return ids
+}
+
+// Primary key values from JSON response body. Returns nil if any of them is missing
+func (s AuditScope) bodyIDs(b []byte) []string {
+	var f map[string]json.RawMessage
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(s.keys))
+	for _, k := range s.keys {
+		if s.field != "" && len(s.keys) == 1 {
+			k = s.field
+		}
+
+		v, ok := f[k]
+		if !ok {
+			return nil
+		}
+
+		// Unquote string values
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			ids = append(ids, str)
+		} else {
+			ids = append(ids, string(v))
+		}
+	}
+
+	return ids
+}
+
+// Audit log action of request method
+func auditAction(m string) string {
+	switch m {
+	case http.MethodPost:
+		return "create"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "update"
+	}
+}
+
+// Row of resource table as JSON object with masked secrets. Returns nil if row not found.
+// Row is locked until end of transaction, so it can't be changed by concurrent requests
+// between precondition checks or read of PATCH and update of the row
+func (h *Handler) auditRow(db godevmandb.DBTX, s AuditScope, ids []string) (map[string]any, error) {
+	cond := make([]string, 0, len(s.keys))
+	args := make([]any, 0, len(ids))
+	for i, k := range s.keys {
+		cond = append(cond, k+" = $"+strconv.Itoa(i+1))
+		args = append(args, ids[i])
+	}
+
+	var b []byte
+	err := db.QueryRow(h.ctx,
+		`SELECT row_to_json(t) FROM `+s.table+` t WHERE `+strings.Join(cond, " AND ")+` FOR UPDATE`,
+		args...).Scan(&b)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	res := make(map[string]any)
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+
+	for k, v := range res {
+		if str, ok := v.(string); ok && auditSecrets[k] {
+			res[k] = maskSecret(str)
+		}
+	}
+
+	return res, nil
+}
+
+// Old and new value of changed field
+type auditDiff struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Changed fields between before and after state of row
+func auditChanges(before, after map[string]any) map[string]auditDiff {
+	res := make(map[string]auditDiff)
+
+	for k, v := range before {
+		if n, ok := after[k]; !ok || !reflect.DeepEqual(v, n) {
+			res[k] = auditDiff{Old: v, New: after[k]}
+		}
+	}
+	for k, v := range after {
+		if _, ok := before[k]; !ok {
+			res[k] = auditDiff{New: v}
+		}
+	}
+
+	return res
+}
+
+// Write audit log record of action made by request user
+func (h *Handler) audit(r *http.Request, action, resource string, id any) error {
+	return h.auditChange(h.db, r, action, resource, fmt.Sprint(id), nil, nil)
+}
+
+// Write audit log record of change made by request user.
+// Record is written using db, which can be transaction of the change
+func (h *Handler) auditChange(db godevmandb.DBTX, r *http.Request, action, resource, id string, before, after map[string]any) error {
+	username := ""
+	if u := requestUser(r); u != nil {
+		username = u.Username
+	}
+
+	// Missing state is stored as NULL
+	var b, a, c any
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	if before != nil || after != nil {
+		c = auditChanges(before, after)
+	}
+
+	_, err := db.Exec(h.ctx,
+		`INSERT INTO audit_log (username, action, route, resource, resource_id, before, after, changes)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		username, action, r.Method+" "+r.URL.Path, resource, id, b, a, c)
+
+	return err
+}
+
+// Apply change of single row in transaction and record it in audit log in the same transaction.
+// Action is create, update or delete. ID of created row is returned by change.
+// Returns errRowNotFound if updated or deleted row does not exist
+func (h *Handler) txAudited(tx pgx.Tx, r *http.Request, s AuditScope, action string, id int64, change func() (int64, error)) (int64, error) {
+	var before map[string]any
+	if action != "create" {
+		var err error
+		before, err = h.auditRow(tx, s, []string{strconv.FormatInt(id, 10)})
+		if err != nil {
+			return 0, err
+		}
+		if before == nil {
+			return 0, errRowNotFound
+		}
+	}
+
+	id, err := change()
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{strconv.FormatInt(id, 10)}
+	var after map[string]any
+	if action != "delete" {
+		after, err = h.auditRow(tx, s, ids)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return id, h.auditChange(tx, r, action, s.table, ids[0], before, after)
+}
+
+// Audit log middleware for write requests.
+// Records before and after state of changed row of successful request.
+// Record is written using database connection of handler, which is transaction of request
+// in routes wrapped by WriteTx. Request fails if record can't be written
+func (h *Handler) Audit(s AuditScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Existing row changed by POST request is identified by payload
+			action := auditAction(r.Method)
+			ids := s.pathIDs(r)
+			if ids == nil && s.payload && r.Method == http.MethodPost {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+					return
+				}
+				r.Body.Close()
+				r.Body = io.NopCloser(bytes.NewReader(b))
+
+				ids = s.bodyIDs(b)
+				action = "update"
+			}
+
+			// State before change
+			var before map[string]any
+			if ids != nil {
+				var err error
+				before, err = h.auditRow(h.db, s, ids)
+				if err != nil {
+					RespondError(w, r, http.StatusInternalServerError, "Audit - "+err.Error())
+					return
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			next.ServeHTTP(rec, r)
+
+			if rec.Code >= http.StatusMultipleChoices {
+				writeRecorded(w, rec)
+				return
+			}
+
+			// Created row ID is taken from response
+			if ids == nil {
+				ids = s.bodyIDs(rec.Body.Bytes())
+			}
+
+			// State after change
+			var after map[string]any
+			if ids != nil && r.Method != http.MethodDelete {
+				var err error
+				after, err = h.auditRow(h.db, s, ids)
+				if err != nil {
+					RespondError(w, r, http.StatusInternalServerError, "Audit - "+err.Error())
+					return
+				}
+			}
+
+			err := h.auditChange(h.db, r, action, s.table, strings.Join(ids, "/"), before, after)
+			if err != nil {
+				RespondError(w, r, http.StatusInternalServerError, "Audit - "+err.Error())
+				return
+			}
+
+			writeRecorded(w, rec)
+		})
+	}
+}
+
+// Audit log record
+type auditRecord struct {
+	CreatedOn  time.Time       `json:"created_on"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	Changes    json.RawMessage `json:"changes" swaggertype:"object"`
+	Username   string          `json:"username"`
+	Action     string          `json:"action"`
+	Route      string          `json:"route"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resource_id"`
+	AuditID    int64           `json:"audit_id"`
+}
+
+// Scan audit log record row
+func (a *auditRecord) scan(row pgx.Row) error {
+	return row.Scan(&a.AuditID, &a.Username, &a.Action, &a.Route, &a.Resource, &a.ResourceID,
+		&a.Before, &a.After, &a.Changes, &a.CreatedOn)
+}
+
+const auditColumns = `audit_id, username, action, route, resource, resource_id, before, after, changes, created_on`
+
+// List query specification of audit log. Newest records first by default
+var auditList = listSpec{
+	table: "audit_log",
+	keys:  []string{"audit_id"},
+	sort:  "-audit_id",
+	model: auditRecord{},
+	filters: []listFilter{
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"username_f", "username", filterILike},
+		{"action_f", "action", filterEq},
+		{"resource_f", "resource", filterILike},
+		{"resource_id_f", "resource_id", filterEq},
+		{"route_f", "route", filterILike},
+	},
+	// JSON documents are not sortable
+	columns: map[string]string{"before": "", "after": "", "changes": ""},
+}
+
+// Count Audit Records
+// @Summary Count audit records
+// @Description Count number of audit log records
+// @Tags audit
+// @ID count-audit
+// @Param username_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param action_f query string false "SQL '=' operator value (create, update, delete, reveal, rotate_key)"
+// @Param resource_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param resource_id_f query string false "SQL '=' operator value"
+// @Param route_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /audit/count [GET]
+func (h *Handler) CountAudit(w http.ResponseWriter, r *http.Request) {
+	l, err := parseListRequest(r, &auditList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List Audit Records
+// @Summary List audit records
+// @Description List audit log records. Newest first by default
+// @Tags audit
+// @ID list-audit
+// @Param username_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param action_f query string false "SQL '=' operator value (create, update, delete, reveal, rotate_key)"
+// @Param resource_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param resource_id_f query string false "SQL '=' operator value"
+// @Param route_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object except before, after and changes is allowed. Default: -audit_id"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} auditRecord
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /audit [GET]
+func (h *Handler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &auditList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[auditRecord](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get Audit Record
+// @Summary Get audit record
+// @Description Get audit log record
+// @Tags audit
+// @ID get-audit
+// @Param audit_id path string true "audit_id"
+// @Success 200 {object} auditRecord
+// @Failure 400 {object} StatusResponse "Invalid audit_id"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Audit record not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /audit/{audit_id} [GET]
+func (h *Handler) GetAuditRecord(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "audit_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid audit record ID")
+		return
+	}
+
+	a := auditRecord{}
+	err = a.scan(h.db.QueryRow(h.ctx, `SELECT `+auditColumns+` FROM audit_log WHERE audit_id = $1`, id))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Audit record not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, a)
+}
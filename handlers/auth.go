@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/httplog"
+)
+
+// Context key of authenticated user
+type authCtxKey struct{}
+
+// Authenticated API user. ExpiresOn is expiry time of API token used in request
+type authUser struct {
+	Domains   map[int64]int32
+	ExpiresOn *time.Time
+	Username  string
+	Userlevel int32
+}
+
+// Return authenticated user of request or nil
+func requestUser(r *http.Request) *authUser {
+	u, _ := r.Context().Value(authCtxKey{}).(*authUser)
+	return u
+}
+
+// API token hash. Tokens are stored as HMAC-SHA256 hex string keyed with token key
+func hashToken(t string) string {
+	return hashTokenWithKey(t, getTokenKey())
+}
+
+// API token hash with given key
+func hashTokenWithKey(t, key string) string {
+	m := hmac.New(sha256.New, []byte(key))
+	m.Write([]byte(t))
+
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Find owner of valid API token and update token last use time.
+// Token hashed with current or previous encryption key (before token key was configured)
+// is rehashed with token key. Empty username means, token owner is not checked
+func (h *Handler) tokenUser(token, username string) (*authUser, error) {
+	hashes := []string{hashToken(token)}
+	cur, old := getKeys()
+	for _, k := range []string{cur, old} {
+		if k != "" {
+			hashes = append(hashes, hashTokenWithKey(token, k))
+		}
+	}
+
+	u := new(authUser)
+	err := h.db.QueryRow(h.ctx,
+		`UPDATE user_tokens t
+		    SET last_used_on = now(), token_hash = $1
+		   FROM users u
+		  WHERE u.username = t.username
+		    AND t.token_hash = ANY($2)
+		    AND ($3 = '' OR t.username = $3)
+		    AND (t.expires_on IS NULL OR t.expires_on > now())
+		RETURNING u.username, u.userlevel, t.expires_on`,
+		hashes[0], hashes, username,
+	).Scan(&u.Username, &u.Userlevel, &u.ExpiresOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// Authentication middleware
+// Accepts bearer API token or HTTP Basic with username and API token as password
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var u *authUser
+		var err error
+
+		if username, pass, ok := r.BasicAuth(); ok {
+			if username != "" && pass != "" {
+				u, err = h.tokenUser(pass, username)
+			}
+		} else if t, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if t = strings.TrimSpace(t); t != "" {
+				u, err = h.tokenUser(t, "")
+			}
+		}
+
+		if err != nil && err.Error() != "no rows in result set" {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if u == nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="godevmanapi", Bearer realm="godevmanapi"`)
+			RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		if err := h.loadDomains(u); err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		httplog.LogEntrySetField(r.Context(), "user", u.Username)
+
+		ctx := context.WithValue(r.Context(), authCtxKey{}, u)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// Minimal userlevel for read access in device domain
+const readLevel int32 = 1
+
+// SQL queries returning resource ID and device domain ID pairs for list of resource IDs
+const (
+	domOfDomain           = `SELECT dom_id, dom_id FROM device_domains WHERE dom_id = ANY($1)`
+	domOfDevice           = `SELECT dev_id, dom_id FROM devices WHERE dev_id = ANY($1)`
+	domOfInterface        = `SELECT i.if_id, d.dom_id FROM interfaces i JOIN devices d ON d.dev_id = i.dev_id WHERE i.if_id = ANY($1)`
+	domOfIfRelation       = `SELECT r.ir_id, d.dom_id FROM interface_relations r JOIN interfaces i ON i.if_id = r.if_id JOIN devices d ON d.dev_id = i.dev_id WHERE r.ir_id = ANY($1)`
+	domOfSubinterface     = `SELECT s.sif_id, d.dom_id FROM subinterfaces s JOIN interfaces i ON i.if_id = s.if_id JOIN devices d ON d.dev_id = i.dev_id WHERE s.sif_id = ANY($1)`
+	domOfIntBwStat        = `SELECT b.bw_id, d.dom_id FROM int_bw_stats b JOIN interfaces i ON i.if_id = b.if_id JOIN devices d ON d.dev_id = i.dev_id WHERE b.bw_id = ANY($1)`
+	domOfEntity           = `SELECT e.ent_id, d.dom_id FROM entities e JOIN devices d ON d.dev_id = e.dev_id WHERE e.ent_id = ANY($1)`
+	domOfEntityPhyIndex   = `SELECT p.ei_id, d.dom_id FROM entity_phy_indexes p JOIN entities e ON e.ent_id = p.ent_id JOIN devices d ON d.dev_id = e.dev_id WHERE p.ei_id = ANY($1)`
+	domOfVlan             = `SELECT v.v_id, d.dom_id FROM vlans v JOIN devices d ON d.dev_id = v.dev_id WHERE v.v_id = ANY($1)`
+	domOfXconnect         = `SELECT x.xc_id, d.dom_id FROM xconnects x JOIN devices d ON d.dev_id = x.dev_id WHERE x.xc_id = ANY($1)`
+	domOfIpInterface      = `SELECT p.ip_id, d.dom_id FROM ip_interfaces p JOIN devices d ON d.dev_id = p.dev_id WHERE p.ip_id = ANY($1)`
+	domOfOspfNbr          = `SELECT n.nbr_id, d.dom_id FROM ospf_nbrs n JOIN devices d ON d.dev_id = n.dev_id WHERE n.nbr_id = ANY($1)`
+	domOfRlNbr            = `SELECT n.nbr_id, d.dom_id FROM rl_nbrs n JOIN devices d ON d.dev_id = n.dev_id WHERE n.nbr_id = ANY($1)`
+	domOfDeviceCredential = `SELECT c.cred_id, d.dom_id FROM device_credentials c JOIN devices d ON d.dev_id = c.dev_id WHERE c.cred_id = ANY($1)`
+	domOfDeviceExtension  = `SELECT x.ext_id, d.dom_id FROM device_extensions x JOIN devices d ON d.dev_id = x.dev_id WHERE x.ext_id = ANY($1)`
+	domOfDeviceLicense    = `SELECT l.lic_id, d.dom_id FROM device_licenses l JOIN devices d ON d.dev_id = l.dev_id WHERE l.lic_id = ANY($1)`
+)
+
+// Row of other resource referred by request payload field.
+// Owner row requires write level in its device domain, peer row requires read level
+type scopeRef struct {
+	field string
+	query string
+	peer  bool
+}
+
+// Device domain scope of resource.
+// Path parameter identifies existing row, body references identify rows referred by payload
+type DomainScope struct {
+	pathParam string
+	pathQuery string
+	body      []scopeRef
+}
+
+// Device domain scopes of device related resources
+var (
+	ScopeDomain           = DomainScope{"dom_id", domOfDomain, nil}
+	ScopeDevice           = DomainScope{"dev_id", domOfDevice, []scopeRef{{"dom_id", domOfDomain, false}, {"parent", domOfDevice, true}}}
+	ScopeInterface        = DomainScope{"if_id", domOfInterface, []scopeRef{{"dev_id", domOfDevice, false}, {"parent", domOfInterface, true}, {"otn_if_id", domOfInterface, true}, {"ent_id", domOfEntity, true}}}
+	ScopeIfRelation       = DomainScope{"ir_id", domOfIfRelation, []scopeRef{{"if_id", domOfInterface, false}, {"if_id_up", domOfInterface, true}, {"if_id_down", domOfInterface, true}}}
+	ScopeSubinterface     = DomainScope{"sif_id", domOfSubinterface, []scopeRef{{"if_id", domOfInterface, false}}}
+	ScopeIntBwStat        = DomainScope{"bw_id", domOfIntBwStat, []scopeRef{{"if_id", domOfInterface, false}}}
+	ScopeEntity           = DomainScope{"ent_id", domOfEntity, []scopeRef{{"dev_id", domOfDevice, false}, {"parent_ent_id", domOfEntity, true}}}
+	ScopeEntityPhyIndex   = DomainScope{"ei_id", domOfEntityPhyIndex, []scopeRef{{"ent_id", domOfEntity, false}}}
+	ScopeVlan             = DomainScope{"v_id", domOfVlan, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeXconnect         = DomainScope{"xc_id", domOfXconnect, []scopeRef{{"dev_id", domOfDevice, false}, {"peer_dev_id", domOfDevice, true}, {"if_id", domOfInterface, true}}}
+	ScopeOtnMapping       = DomainScope{"if_id", domOfInterface, []scopeRef{{"if_id", domOfInterface, false}, {"otn_if_id", domOfInterface, true}}}
+	ScopeIpInterface      = DomainScope{"ip_id", domOfIpInterface, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeOspfNbr          = DomainScope{"nbr_id", domOfOspfNbr, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeRlNbr            = DomainScope{"nbr_id", domOfRlNbr, []scopeRef{{"dev_id", domOfDevice, false}, {"nbr_ent_id", domOfEntity, true}}}
+	ScopeDeviceCredential = DomainScope{"cred_id", domOfDeviceCredential, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeDeviceExtension  = DomainScope{"ext_id", domOfDeviceExtension, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeDeviceLicense    = DomainScope{"lic_id", domOfDeviceLicense, []scopeRef{{"dev_id", domOfDevice, false}}}
+	ScopeDeviceState      = DomainScope{"dev_id", domOfDevice, []scopeRef{{"dev_id", domOfDevice, false}}}
+)
+
+// Scope of resources shared by device domains. Writes require global write level
+var ScopeShared = DomainScope{}
+
+// Return true if scope is scope of resources shared by device domains
+func (s DomainScope) isShared() bool {
+	return s.pathQuery == ""
+}
+
+// Return true if user has global admin userlevel
+func (u *authUser) isAdmin() bool {
+	return u.Userlevel >= adminLevel
+}
+
+// Return true if user has global write userlevel
+func (u *authUser) canWrite() bool {
+	return u.Userlevel >= writeLevel || u.isAdmin()
+}
+
+// Userlevel of user in device domain
+func (u *authUser) domainLevel(domID int64) int32 {
+	if u.isAdmin() {
+		return u.Userlevel
+	}
+
+	return u.Domains[domID]
+}
+
+// Load device domain userlevels of user
+func (h *Handler) loadDomains(u *authUser) error {
+	q := godevmandb.New(h.db)
+	res, err := q.GetUserUserAuthzs(h.ctx, u.Username)
+	if err != nil {
+		return err
+	}
+
+	u.Domains = make(map[int64]int32)
+	for _, a := range res {
+		u.Domains[a.DomID] = int32(a.Userlevel)
+	}
+
+	return nil
+}
+
+// Device domains of resource rows. Returns map of resource ID to domain ID
+func (h *Handler) rowDomains(query string, ids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64)
+
+	rows, err := h.db.Query(h.ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, domID int64
+		if err := rows.Scan(&id, &domID); err != nil {
+			return nil, err
+		}
+		res[id] = domID
+	}
+
+	return res, rows.Err()
+}
+
+// Remove rows which are not in device domains readable by user.
+// id returns ID used in query for row. Rows not found by query are removed.
+func authzFilter[T any](h *Handler, r *http.Request, query string, rows []T, id func(T) int64) ([]T, error) {
+	u := requestUser(r)
+	if u == nil {
+		return []T{}, nil
+	}
+
+	if u.isAdmin() || len(rows) == 0 {
+		return rows, nil
+	}
+
+	ids := make([]int64, 0, len(rows))
+	for _, s := range rows {
+		ids = append(ids, id(s))
+	}
+
+	doms, err := h.rowDomains(query, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, 0, len(rows))
+	for _, s := range rows {
+		if d, ok := doms[id(s)]; ok && u.domainLevel(d) >= readLevel {
+			out = append(out, s)
+		}
+	}
+
+	return out, nil
+}
+
+// Check userlevel of user in device domain of resource row.
+// Returns false if user is not allowed to access the row. Missing row is allowed
+// outside of transaction where handler reports it. In transaction rows are read
+// through it, so rows created by earlier changes of request are found and
+// missing row is denied with errRowNotFound
+func (h *Handler) authzRow(u *authUser, query string, id int64, level int32) (bool, error) {
+	if u.isAdmin() {
+		return true, nil
+	}
+
+	doms, err := h.rowDomains(query, []int64{id})
+	if err != nil {
+		return false, err
+	}
+
+	d, ok := doms[id]
+	if !ok {
+		if h.inTx() {
+			return false, errRowNotFound
+		}
+		return true, nil
+	}
+
+	return u.domainLevel(d) >= level, nil
+}
+
+// Check userlevel of user in device domains of rows referred by payload fields f.
+// Returns false if user is not allowed to refer any of the rows or referred row is missing in transaction
+func (h *Handler) authzRefs(u *authUser, s DomainScope, f map[string]json.RawMessage) (bool, error) {
+	for _, ref := range s.body {
+		id, err := strconv.ParseInt(string(f[ref.field]), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		level := writeLevel
+		if ref.peer {
+			level = readLevel
+		}
+
+		ok, err := h.authzRow(u, ref.query, id, level)
+		if errors.Is(err, errRowNotFound) {
+			return false, nil
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Device domain authorization middleware.
+// Read requires read level in device domain, write requires write level
+// in device domain of existing row and in device domain of row referred by payload.
+func (h *Handler) AuthorizeDomain(s DomainScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u := requestUser(r)
+			if u == nil {
+				RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			level := writeLevel
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				level = readLevel
+			}
+
+			// Existing row
+			if v := chi.URLParam(r, s.pathParam); v != "" {
+				id, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					RespondError(w, r, http.StatusBadRequest, "Invalid "+s.pathParam)
+					return
+				}
+
+				ok, err := h.authzRow(u, s.pathQuery, id, level)
+				if errors.Is(err, errRowNotFound) {
+					RespondError(w, r, http.StatusNotFound, "Resource not found")
+					return
+				}
+				if err != nil {
+					RespondError(w, r, http.StatusInternalServerError, err.Error())
+					return
+				}
+				if !ok {
+					RespondError(w, r, http.StatusForbidden, "Access denied")
+					return
+				}
+			}
+
+			// Rows referred by payload
+			if len(s.body) > 0 && (r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+					return
+				}
+				r.Body.Close()
+				r.Body = io.NopCloser(bytes.NewReader(b))
+
+				var f map[string]json.RawMessage
+				if err := json.Unmarshal(b, &f); err == nil {
+					ok, err := h.authzRefs(u, s, f)
+					if err != nil {
+						RespondError(w, r, http.StatusInternalServerError, err.Error())
+						return
+					}
+					if !ok {
+						RespondError(w, r, http.StatusForbidden, "Access denied")
+						return
+					}
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Global write level authorization middleware for write requests of resources shared by device domains
+func (h *Handler) AuthorizeWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if u := requestUser(r); u == nil || !u.canWrite() {
+				RespondError(w, r, http.StatusForbidden, "Access denied")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Admin authorization middleware for write requests
+func (h *Handler) AuthorizeAdminWrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if u := requestUser(r); u == nil || !u.isAdmin() {
+				RespondError(w, r, http.StatusForbidden, "Access denied")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Admin authorization middleware
+func (h *Handler) AuthorizeAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u := requestUser(r); u == nil || !u.isAdmin() {
+			RespondError(w, r, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authorization middleware for revealing secrets
+func (h *Handler) AuthorizeReveal(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u := requestUser(r); u == nil || u.Userlevel < revealLevel {
+			RespondError(w, r, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
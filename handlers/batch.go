@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Maximum number of operations in batch request
+const batchMaxOps = 1000
+
+// Reference to field of earlier operation response, eg. ${0.if_id}
+var batchRef = regexp.MustCompile(`\$\{([0-9]+)\.([A-Za-z0-9_]+)\}`)
+
+// Batch request operation. Path is route of API without host part, eg. /devices/12.
+// Path and string values of body may refer to response fields of earlier operations
+// as ${<index>.<field>}. Body string consisting only of reference gets type of referred value
+type batchOp struct {
+	Method string          `json:"method" enums:"GET,POST,PUT,PATCH,DELETE"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// Result of batch request operation
+type batchResult struct {
+	Index  int    `json:"index"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// Response of batch request. Changes are committed only if all operations succeed
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []batchResult `json:"results"`
+}
+
+// Resolve references in string.
+// Returns referred value as is if string consists only of single reference
+func batchResolve(s string, res []batchResult) (any, error) {
+	var rerr error
+	lookup := func(m []string) any {
+		i, _ := strconv.Atoi(m[1])
+		if i >= len(res) {
+			rerr = fmt.Errorf("Reference to unknown operation %s", m[1])
+			return nil
+		}
+
+		o, ok := res[i].Body.(map[string]any)
+		if !ok || o[m[2]] == nil {
+			rerr = fmt.Errorf("Reference to unknown field %s of operation %s", m[2], m[1])
+			return nil
+		}
+
+		return o[m[2]]
+	}
+
+	if m := batchRef.FindStringSubmatch(s); m != nil && m[0] == s {
+		v := lookup(m)
+		return v, rerr
+	}
+
+	out := batchRef.ReplaceAllStringFunc(s, func(v string) string {
+		return fmt.Sprint(lookup(batchRef.FindStringSubmatch(v)))
+	})
+
+	return out, rerr
+}
+
+// Resolve references in JSON value
+func batchResolveValue(v any, res []batchResult) (any, error) {
+	switch t := v.(type) {
+	case string:
+		return batchResolve(t, res)
+	case map[string]any:
+		for k, e := range t {
+			r, err := batchResolveValue(e, res)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = r
+		}
+	case []any:
+		for i, e := range t {
+			r, err := batchResolveValue(e, res)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = r
+		}
+	}
+
+	return v, nil
+}
+
+// Build request of batch operation
+func batchRequest(ctx context.Context, op batchOp, res []batchResult) (*http.Request, error) {
+	switch op.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return nil, errors.New("Invalid method")
+	}
+
+	p, err := batchResolve(op.Path, res)
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprint(p)
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("Invalid path")
+	}
+
+	var body []byte
+	if len(op.Body) > 0 {
+		v, err := decodeJSONValue(op.Body)
+		if err != nil {
+			return nil, errors.New("Invalid body")
+		}
+		if v, err = batchResolveValue(v, res); err != nil {
+			return nil, err
+		}
+		if body, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	// Operation is routed by its own route context
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, nil)
+	req, err := http.NewRequestWithContext(ctx, op.Method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.New("Invalid path")
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// Batch operations
+// @Summary Batch operations
+// @Description Run ordered list of operations against API routes in single transaction.
+// @Description Path and string values of body may refer to response fields of earlier operations as ${<index>.<field>},
+// @Description eg. {"method": "POST", "path": "/interfaces/${0.if_id}/..."}.
+// @Description If any operation fails, all changes are rolled back and response status is status of failed operation
+// @Tags batch
+// @ID batch
+// @Param Body body []batchOp true "JSON array of batchOp"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 401 {object} StatusResponse "Authentication required"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /batch [POST]
+func (h *Handler) Batch(routes func(*Handler) http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if requestUser(r) == nil {
+			RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		var ops []batchOp
+		decoder := json.NewDecoder(r.Body)
+		if err := decoder.Decode(&ops); err != nil || len(ops) == 0 || len(ops) > batchMaxOps {
+			RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		defer r.Body.Close()
+
+		tx, err := h.db.Begin(h.ctx)
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer tx.Rollback(h.ctx)
+
+		router := routes(h.WithTx(tx))
+		res := BatchResponse{Results: make([]batchResult, 0, len(ops))}
+		for i, op := range ops {
+			out := batchResult{Index: i, Method: op.Method, Path: op.Path}
+
+			req, err := batchRequest(r.Context(), op, res.Results)
+			if err != nil {
+				out.Status = http.StatusBadRequest
+				out.Body = StatusResponse{Code: strconv.Itoa(out.Status), Message: err.Error()}
+				res.Results = append(res.Results, out)
+				RespondJSON(w, r, out.Status, res)
+				return
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			out.Path = req.URL.String()
+			out.Status = rec.Code
+			if b, err := io.ReadAll(rec.Body); err == nil && len(b) > 0 {
+				if v, err := decodeJSONValue(b); err == nil {
+					out.Body = v
+				}
+			}
+			res.Results = append(res.Results, out)
+
+			if rec.Code >= http.StatusBadRequest {
+				RespondJSON(w, r, rec.Code, res)
+				return
+			}
+		}
+
+		if err := tx.Commit(h.ctx); err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		res.Committed = true
+
+		RespondJSON(w, r, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/jackc/pgx/v4"
+)
+
+// Maximum number of items in bulk request
+const bulkMaxItems = 1000
+
+// Error of undecodable bulk item data
+var errBulkPayload = errors.New("Invalid item payload")
+
+// Bulk request item. Data is JSON object of resource for create and update
+type bulkItem struct {
+	Op   string          `json:"op" enums:"create,update,delete"`
+	ID   int64           `json:"id"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// Result of bulk request item
+type bulkResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	Data   any    `json:"data,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Response of bulk request. Changes are committed only if all items succeed
+type BulkResponse struct {
+	Committed bool         `json:"committed"`
+	Results   []bulkResult `json:"results"`
+}
+
+// Bulk operations of resource. Rows of resource without domain scope are not authorized.
+// Create and update decode item data and return ID and response object of changed row
+type bulkSpec struct {
+	scope  DomainScope
+	audit  AuditScope
+	create func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error)
+	update func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error)
+	delete func(h *Handler, q *godevmandb.Queries, id int64) error
+}
+
+// Decode JSON data of bulk item
+func bulkDecode(b []byte, v any) error {
+	if err := json.Unmarshal(b, v); err != nil {
+		return errBulkPayload
+	}
+
+	return nil
+}
+
+// Apply bulk item in transaction. Returns ID and response object of changed row and status code
+func (h *Handler) bulkApply(tx pgx.Tx, r *http.Request, s bulkSpec, it bulkItem) (int64, any, int, error) {
+	u := requestUser(r)
+	q := godevmandb.New(h.db).WithTx(tx)
+	// Authorize through transaction to see rows changed by earlier items
+	th := h.WithTx(tx)
+
+	var status int
+	switch it.Op {
+	case "create":
+		status = http.StatusCreated
+	case "update":
+		status = http.StatusOK
+	case "delete":
+		status = http.StatusNoContent
+	default:
+		return 0, nil, http.StatusBadRequest, errors.New("Invalid op")
+	}
+
+	// Shared resource or existing row
+	if s.scope.isShared() {
+		if !u.canWrite() {
+			return 0, nil, http.StatusForbidden, errors.New("Access denied")
+		}
+	} else if it.Op != "create" {
+		ok, err := th.authzRow(u, s.scope.pathQuery, it.ID, writeLevel)
+		if errors.Is(err, errRowNotFound) {
+			return 0, nil, http.StatusNotFound, err
+		}
+		if err != nil {
+			return 0, nil, http.StatusInternalServerError, err
+		}
+		if !ok {
+			return 0, nil, http.StatusForbidden, errors.New("Access denied")
+		}
+	}
+
+	// Rows referred by data
+	if it.Op != "delete" {
+		var f map[string]json.RawMessage
+		if err := json.Unmarshal(it.Data, &f); err != nil {
+			return 0, nil, http.StatusBadRequest, errBulkPayload
+		}
+
+		ok, err := th.authzRefs(u, s.scope, f)
+		if err != nil {
+			return 0, nil, http.StatusInternalServerError, err
+		}
+		if !ok {
+			return 0, nil, http.StatusForbidden, errors.New("Access denied")
+		}
+	}
+
+	var out any
+	id, err := h.txAudited(tx, r, s.audit, it.Op, it.ID, func() (int64, error) {
+		var err error
+		switch it.Op {
+		case "create":
+			var id int64
+			id, out, err = s.create(h, q, it.Data)
+			return id, err
+		case "update":
+			out, err = s.update(h, q, it.ID, it.Data)
+		default:
+			err = s.delete(h, q, it.ID)
+		}
+		return it.ID, err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errBulkPayload):
+			return 0, nil, http.StatusBadRequest, err
+		case errors.Is(err, errRowNotFound):
+			return 0, nil, http.StatusNotFound, err
+		}
+		return 0, nil, http.StatusInternalServerError, err
+	}
+
+	return id, out, status, nil
+}
+
+// Handle bulk request of resource.
+// Items are applied in order in single transaction. If any item fails, all changes
+// are rolled back and response status is status of failed item
+func (h *Handler) bulk(w http.ResponseWriter, r *http.Request, s bulkSpec) {
+	if requestUser(r) == nil {
+		RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var items []bulkItem
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&items); err != nil || len(items) == 0 || len(items) > bulkMaxItems {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	tx, err := h.db.Begin(h.ctx)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(h.ctx)
+
+	res := BulkResponse{Results: make([]bulkResult, 0, len(items))}
+	for i, it := range items {
+		id, out, status, err := h.bulkApply(tx, r, s, it)
+		if err != nil {
+			res.Results = append(res.Results, bulkResult{Index: i, Op: it.Op, Status: status, ID: it.ID, Error: err.Error()})
+			RespondJSON(w, r, status, res)
+			return
+		}
+
+		res.Results = append(res.Results, bulkResult{Index: i, Op: it.Op, Status: status, ID: id, Data: out})
+	}
+
+	if err := tx.Commit(h.ctx); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	res.Committed = true
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
@@ -11,17 +11,30 @@ import (
 
 // Count ConCapacities
 // @Summary Count con_capacities
-// @Description Count number of connection capacities
+// @Description Count number of connection capacities matching filters
 // @Tags connections
 // @ID count-con_capacities
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/capacities/count [GET]
 func (h *Handler) CountConCapacities(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountConCapacities(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &conCapacitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +43,21 @@ func (h *Handler) CountConCapacities(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of con capacities
+var conCapacitiesList = listSpec{
+	table: "con_capacities",
+	keys:  []string{"con_cap_id"},
+	model: godevmandb.ConCapacity{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List con_capacities
 // @Summary List con_capacities
 // @Description List connection capacities info
@@ -38,58 +66,39 @@ func (h *Handler) CountConCapacities(w http.ResponseWriter, r *http.Request) {
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.ConCapacity
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/capacities [GET]
 func (h *Handler) GetConCapacities(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetConCapacitiesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &conCapacitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetConCapacities(h.ctx, p)
+	res, page, err := listRows[godevmandb.ConCapacity](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get ConCapacity
@@ -98,7 +107,10 @@ func (h *Handler) GetConCapacities(w http.ResponseWriter, r *http.Request) {
 // @Tags connections
 // @ID get-capacity
 // @Param con_cap_id path string true "con_cap_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.ConCapacity
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid con_cap_id"
 // @Failure 404 {object} StatusResponse "Capacity not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -164,10 +176,13 @@ func (h *Handler) CreateConCapacity(w http.ResponseWriter, r *http.Request) {
 // @ID update-capacity
 // @Param con_cap_id path string true "con_cap_id"
 // @Param Body body godevmandb.UpdateConCapacityParams true "JSON object of godevmandb.UpdateConCapacityParams.<br />Ignored fields:<ul><li>con_cap_id</li>/ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.ConCapacity
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/capacities/{con_cap_id} [PUT]
 func (h *Handler) UpdateConCapacity(w http.ResponseWriter, r *http.Request) {
@@ -198,16 +213,39 @@ func (h *Handler) UpdateConCapacity(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch ConCapacity
+// @Summary Patch capacity
+// @Description Partially update capacity with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags connections
+// @ID patch-capacity
+// @Param con_cap_id path string true "con_cap_id"
+// @Param Body body godevmandb.UpdateConCapacityParams true "JSON merge patch of godevmandb.UpdateConCapacityParams.<br />Ignored fields:<ul><li>con_cap_id</li>/ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.ConCapacity
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /connections/capacities/{con_cap_id} [PATCH]
+func (h *Handler) PatchConCapacity(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetConCapacity, h.UpdateConCapacity)
+}
+
 // Delete ConCapacity
 // @Summary Delete capacity
 // @Description Delete connection capacity
 // @Tags connections
 // @ID delete-capacity
 // @Param con_cap_id path string true "con_cap_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid con_cap_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/capacities/{con_cap_id} [DELETE]
 func (h *Handler) DeleteConCapacity(w http.ResponseWriter, r *http.Request) {
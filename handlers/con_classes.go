@@ -11,17 +11,30 @@ import (
 
 // Count ConClasses
 // @Summary Count con_classes
-// @Description Count number of connection classes
+// @Description Count number of connection classes matching filters
 // @Tags connections
 // @ID count-con_classes
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/classes/count [GET]
 func (h *Handler) CountConClasses(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountConClasses(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &conClassesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +43,21 @@ func (h *Handler) CountConClasses(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of con classes
+var conClassesList = listSpec{
+	table: "con_classes",
+	keys:  []string{"con_class_id"},
+	model: godevmandb.ConClass{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List con_classes
 // @Summary List con_classes
 // @Description List connection classes info
@@ -38,58 +66,39 @@ func (h *Handler) CountConClasses(w http.ResponseWriter, r *http.Request) {
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.ConClass
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/classes [GET]
 func (h *Handler) GetConClasses(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetConClassesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &conClassesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetConClasses(h.ctx, p)
+	res, page, err := listRows[godevmandb.ConClass](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get ConClass
@@ -98,7 +107,10 @@ func (h *Handler) GetConClasses(w http.ResponseWriter, r *http.Request) {
 // @Tags connections
 // @ID get-con_class
 // @Param con_class_id path string true "con_class_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.ConClass
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid con_class_id"
 // @Failure 404 {object} StatusResponse "Class not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -164,10 +176,13 @@ func (h *Handler) CreateConClass(w http.ResponseWriter, r *http.Request) {
 // @ID update-con_class
 // @Param con_class_id path string true "con_class_id"
 // @Param Body body godevmandb.UpdateConClassParams true "JSON object of godevmandb.UpdateConClassParams.<br />Ignored fields:<ul><li>con_class_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.ConClass
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/classes/{con_class_id} [PUT]
 func (h *Handler) UpdateConClass(w http.ResponseWriter, r *http.Request) {
@@ -198,16 +213,39 @@ func (h *Handler) UpdateConClass(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch ConClass
+// @Summary Patch con_class
+// @Description Partially update con_class with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags connections
+// @ID patch-con_class
+// @Param con_class_id path string true "con_class_id"
+// @Param Body body godevmandb.UpdateConClassParams true "JSON merge patch of godevmandb.UpdateConClassParams.<br />Ignored fields:<ul><li>con_class_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.ConClass
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /connections/classes/{con_class_id} [PATCH]
+func (h *Handler) PatchConClass(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetConClass, h.UpdateConClass)
+}
+
 // Delete ConClass
 // @Summary Delete con_class
 // @Description Delete connection class
 // @Tags connections
 // @ID delete-con_class
 // @Param con_class_id path string true "con_class_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid con_class_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/classes/{con_class_id} [DELETE]
 func (h *Handler) DeleteConClass(w http.ResponseWriter, r *http.Request) {
@@ -11,17 +11,30 @@ import (
 
 // Count ConProviders
 // @Summary Count con_providers
-// @Description Count number of connection providers
+// @Description Count number of connection providers matching filters
 // @Tags connections
 // @ID count-con_providers
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/providers/count [GET]
 func (h *Handler) CountConProviders(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountConProviders(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &conProvidersList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +43,21 @@ func (h *Handler) CountConProviders(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of con providers
+var conProvidersList = listSpec{
+	table: "con_providers",
+	keys:  []string{"con_prov_id"},
+	model: godevmandb.ConProvider{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List con_providers
 // @Summary List con_providers
 // @Description List connection providers info
@@ -38,58 +66,39 @@ func (h *Handler) CountConProviders(w http.ResponseWriter, r *http.Request) {
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.ConProvider
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/providers [GET]
 func (h *Handler) GetConProviders(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetConProvidersParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &conProvidersList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetConProviders(h.ctx, p)
+	res, page, err := listRows[godevmandb.ConProvider](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get ConProvider
@@ -98,7 +107,10 @@ func (h *Handler) GetConProviders(w http.ResponseWriter, r *http.Request) {
 // @Tags connections
 // @ID get-con_provider
 // @Param con_prov_id path string true "con_prov_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.ConProvider
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid con_prov_id"
 // @Failure 404 {object} StatusResponse "Provider not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -164,10 +176,13 @@ func (h *Handler) CreateConProvider(w http.ResponseWriter, r *http.Request) {
 // @ID update-con_provider
 // @Param con_prov_id path string true "con_prov_id"
 // @Param Body body godevmandb.UpdateConProviderParams true "JSON object of godevmandb.UpdateConProviderParams.<br />Ignored fields:<ul><li>con_prov_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.ConProvider
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/providers/{con_prov_id} [PUT]
 func (h *Handler) UpdateConProvider(w http.ResponseWriter, r *http.Request) {
@@ -198,16 +213,39 @@ func (h *Handler) UpdateConProvider(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch ConProvider
+// @Summary Patch con_provider
+// @Description Partially update con_provider with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags connections
+// @ID patch-con_provider
+// @Param con_prov_id path string true "con_prov_id"
+// @Param Body body godevmandb.UpdateConProviderParams true "JSON merge patch of godevmandb.UpdateConProviderParams.<br />Ignored fields:<ul><li>con_prov_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.ConProvider
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /connections/providers/{con_prov_id} [PATCH]
+func (h *Handler) PatchConProvider(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetConProvider, h.UpdateConProvider)
+}
+
 // Delete ConProvider
 // @Summary Delete con_provider
 // @Description Delete connection provider
 // @Tags connections
 // @ID delete-con_provider
 // @Param con_prov_id path string true "con_prov_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid con_prov_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/providers/{con_prov_id} [DELETE]
 func (h *Handler) DeleteConProvider(w http.ResponseWriter, r *http.Request) {
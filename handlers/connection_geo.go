@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/aretaja/godevmandb"
+)
+
+// GeoJSON geometry of connection. Point, LineString or MultiLineString
+type geoGeometry struct {
+	Type        string `json:"type" example:"LineString"`
+	Coordinates any    `json:"coordinates" swaggertype:"array,number"`
+}
+
+// Properties of connection feature
+type connectionProperties struct {
+	godevmandb.Connection
+	SiteIDs []int64 `json:"site_ids"`
+}
+
+// GeoJSON feature of connection. Geometry is null if no endpoint site has coordinates
+type connectionFeature struct {
+	Type       string               `json:"type" example:"Feature"`
+	ID         int64                `json:"id"`
+	Geometry   *geoGeometry         `json:"geometry"`
+	Properties connectionProperties `json:"properties"`
+}
+
+// GeoJSON feature collection of connections
+type ConnectionFeatureCollection struct {
+	Type     string              `json:"type" example:"FeatureCollection"`
+	Features []connectionFeature `json:"features"`
+}
+
+// Parse location filter of connections list.
+// bbox is "min_lon,min_lat,max_lon,max_lat". Connection matches if its site or site of
+// any readable device with interface on connection is in bbox
+func connectionGeoFilters(r *http.Request, l *listRequest) error {
+	b, err := parseBbox(r)
+	if err != nil || b == nil {
+		return err
+	}
+
+	args := []any{b[0], b[1], b[2], b[3]}
+	devs := ""
+	if doms, all := readDomains(r); !all {
+		devs = " AND d.dom_id = ANY($%[5]d)"
+		args = append(args, doms)
+	}
+
+	l.addN(`(EXISTS (SELECT 1 FROM sites s WHERE s.site_id = t.site_id AND `+bboxCondition("s")+`) OR `+
+		`EXISTS (SELECT 1 FROM interfaces i JOIN devices d ON d.dev_id = i.dev_id JOIN sites s ON s.site_id = d.site_id `+
+		`WHERE i.con_id = t.con_id AND `+bboxCondition("s")+devs+`))`, args...)
+
+	return nil
+}
+
+// Geometry of connection endpoints. Single endpoint is Point, two endpoints are LineString and
+// more endpoints are MultiLineString of lines from first endpoint to others
+func connectionGeometry(pts [][2]float64) *geoGeometry {
+	switch len(pts) {
+	case 0:
+		return nil
+	case 1:
+		return &geoGeometry{Type: "Point", Coordinates: pts[0]}
+	case 2:
+		return &geoGeometry{Type: "LineString", Coordinates: pts}
+	}
+
+	lines := make([][][2]float64, 0, len(pts)-1)
+	for _, p := range pts[1:] {
+		lines = append(lines, [][2]float64{pts[0], p})
+	}
+
+	return &geoGeometry{Type: "MultiLineString", Coordinates: lines}
+}
+
+// Endpoint site of connection
+type connectionSite struct {
+	id        int64
+	latitude  *float32
+	longitude *float32
+}
+
+// Endpoint sites of connections. Connection site is first, followed by sites of devices
+// with interface on connection. Only devices readable by user are included
+func (h *Handler) connectionSites(r *http.Request, ids []int64) (map[int64][]connectionSite, error) {
+	res := make(map[int64][]connectionSite)
+
+	query := `SELECT c.con_id, 0 AS ord, s.site_id, s.latitude, s.longitude
+	            FROM connections c
+	            JOIN sites s ON s.site_id = c.site_id
+	           WHERE c.con_id = ANY($1)
+	          UNION ALL
+	          SELECT i.con_id, 1, s.site_id, s.latitude, s.longitude
+	            FROM interfaces i
+	            JOIN devices d ON d.dev_id = i.dev_id
+	            JOIN sites s ON s.site_id = d.site_id
+	           WHERE i.con_id = ANY($1)`
+	args := []any{ids}
+	if doms, all := readDomains(r); !all {
+		query += ` AND d.dom_id = ANY($2)`
+		args = append(args, doms)
+	}
+
+	rows, err := h.db.Query(h.ctx, query+` ORDER BY 1, 2, 3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[[2]int64]bool)
+	for rows.Next() {
+		var (
+			con, ord int64
+			s        connectionSite
+		)
+		if err := rows.Scan(&con, &ord, &s.id, &s.latitude, &s.longitude); err != nil {
+			return nil, err
+		}
+		if k := [2]int64{con, s.id}; !seen[k] {
+			seen[k] = true
+			res[con] = append(res[con], s)
+		}
+	}
+
+	return res, rows.Err()
+}
+
+// Respond with GeoJSON feature collection of connections
+func (h *Handler) respondConnectionsGeoJSON(w http.ResponseWriter, r *http.Request, page listPage, cons []godevmandb.Connection) {
+	ids := make([]int64, 0, len(cons))
+	for _, c := range cons {
+		ids = append(ids, c.ConID)
+	}
+
+	sites, err := h.connectionSites(r, ids)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := ConnectionFeatureCollection{Type: "FeatureCollection", Features: make([]connectionFeature, 0, len(cons))}
+	for _, c := range cons {
+		siteIDs := make([]int64, 0, len(sites[c.ConID]))
+		pts := [][2]float64{}
+		for _, s := range sites[c.ConID] {
+			siteIDs = append(siteIDs, s.id)
+			if s.latitude != nil && s.longitude != nil {
+				pts = append(pts, [2]float64{geoCoord(*s.longitude), geoCoord(*s.latitude)})
+			}
+		}
+
+		out.Features = append(out.Features, connectionFeature{
+			Type:       "Feature",
+			ID:         c.ConID,
+			Geometry:   connectionGeometry(pts),
+			Properties: connectionProperties{Connection: c, SiteIDs: siteIDs},
+		})
+	}
+
+	res, err := json.Marshal(out)
+	if err != nil {
+		log.Print(err.Error())
+		RespondError(w, r, http.StatusInternalServerError, "JSON marshal failed")
+		return
+	}
+
+	setPageHeaders(w, r, page)
+	w.Header().Set("Content-Type", geoJSONType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
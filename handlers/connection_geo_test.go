@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestConnectionGeometry(t *testing.T) {
+	a, b, c := [2]float64{24.75, 59.43}, [2]float64{26.72, 58.38}, [2]float64{24.5, 58.38}
+
+	tests := []struct {
+		name string
+		pts  [][2]float64
+		want *geoGeometry
+	}{
+		{"no coordinates", [][2]float64{}, nil},
+		{"single site", [][2]float64{a}, &geoGeometry{Type: "Point", Coordinates: a}},
+		{"two sites", [][2]float64{a, b}, &geoGeometry{Type: "LineString", Coordinates: [][2]float64{a, b}}},
+		{"three sites", [][2]float64{a, b, c}, &geoGeometry{Type: "MultiLineString",
+			Coordinates: [][][2]float64{{a, b}, {a, c}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := connectionGeometry(tt.pts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("connectionGeometry(%v) = %v, want %v", tt.pts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectionGeoFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		err   bool
+	}{
+		{"no filters", "", nil, false},
+		{"bbox", "bbox=24.5,59.3,25.0,59.6", []any{24.5, 59.3, 25.0, 59.6, []int64{}}, false},
+		{"bbox min above max", "bbox=25.0,59.3,24.5,59.6", nil, true},
+		{"bbox too few values", "bbox=24.5,59.3,25.0", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/connections?"+tt.query, nil)
+			l := &listRequest{}
+			err := connectionGeoFilters(r, l)
+			if (err != nil) != tt.err {
+				t.Fatalf("connectionGeoFilters(%q) error = %v, want error %v", tt.query, err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(l.args, tt.args) {
+				t.Errorf("connectionGeoFilters(%q) args = %v, want %v", tt.query, l.args, tt.args)
+			}
+		})
+	}
+}
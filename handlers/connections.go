@@ -11,17 +11,32 @@ import (
 
 // Count Connections
 // @Summary Count connections
-// @Description Count number of connections
+// @Description Count number of connections matching filters
 // @Tags connections
 // @ID count-connections
+// @Param hint_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param in_use_f query bool false "values 'true', 'false'"
+// @Param bbox query string false "connections with endpoint site in bounding box 'min_lon,min_lat,max_lon,max_lat'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/count [GET]
 func (h *Handler) CountConnections(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountConnections(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &connectionsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,71 +45,71 @@ func (h *Handler) CountConnections(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of connections
+var connectionsList = listSpec{
+	table: "connections",
+	keys:  []string{"con_id"},
+	model: godevmandb.Connection{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"hint_f", "hint", filterILikeNull},
+		{"notes_f", "notes", filterILikeNull},
+		{"in_use_f", "in_use", filterBool},
+	},
+	params: connectionGeoFilters,
+}
+
 // List connections
 // @Summary List connections
-// @Description List connection info
+// @Description List connection info. With format=geojson response is ConnectionFeatureCollection
 // @Tags connections
 // @ID list-connections
 // @Param hint_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param in_use_f query bool false "values 'true', 'false'"
+// @Param bbox query string false "connections with endpoint site in bounding box 'min_lon,min_lat,max_lon,max_lat'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row, 'geojson' for GeoJSON FeatureCollection with lines between endpoint sites. Alternatively set Accept header \"text/csv\" or \"application/geo+json\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Connection
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections [GET]
 func (h *Handler) GetConnections(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetConnectionsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("hint_f"); v != "" {
-		p.HintF = &v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
-	}
-
-	if v := r.FormValue("in_use_f"); v != "" {
-		p.InUseF = v
+	// Query DB
+	l, err := parseListRequest(r, &connectionsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetConnections(h.ctx, p)
+	res, page, err := listRows[godevmandb.Connection](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	if wantGeoJSON(r) {
+		h.respondConnectionsGeoJSON(w, r, page, res)
+		return
+	}
+
+	respondList(w, r, page, res)
 }
 
 // Get Connection
@@ -103,7 +118,10 @@ func (h *Handler) GetConnections(w http.ResponseWriter, r *http.Request) {
 // @Tags connections
 // @ID get-connection
 // @Param con_id path string true "con_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Connection
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid con_id"
 // @Failure 404 {object} StatusResponse "Connection not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -169,10 +187,13 @@ func (h *Handler) CreateConnection(w http.ResponseWriter, r *http.Request) {
 // @ID update-connection
 // @Param con_id path string true "con_id"
 // @Param Body body godevmandb.UpdateConnectionParams true "JSON object of godevmandb.UpdateConnectionParams.<br />Ignored fields:<ul><li>con_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Connection
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/{con_id} [PUT]
 func (h *Handler) UpdateConnection(w http.ResponseWriter, r *http.Request) {
@@ -204,16 +225,39 @@ func (h *Handler) UpdateConnection(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Connection
+// @Summary Patch connection
+// @Description Partially update connection with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags connections
+// @ID patch-connection
+// @Param con_id path string true "con_id"
+// @Param Body body godevmandb.UpdateConnectionParams true "JSON merge patch of godevmandb.UpdateConnectionParams.<br />Ignored fields:<ul><li>con_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Connection
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /connections/{con_id} [PATCH]
+func (h *Handler) PatchConnection(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetConnection, h.UpdateConnection)
+}
+
 // Delete Connection
 // @Summary Delete connection
 // @Description Delete connection
 // @Tags connections
 // @ID delete-connection
 // @Param con_id path string true "con_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid con_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /connections/{con_id} [DELETE]
 func (h *Handler) DeleteConnection(w http.ResponseWriter, r *http.Request) {
@@ -233,6 +277,38 @@ func (h *Handler) DeleteConnection(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of connections
+var connectionsBulk = bulkSpec{
+	scope: ScopeShared,
+	audit: AuditConnection,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateConnectionParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateConnection(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.ConID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateConnectionParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.ConID = id
+
+		return q.UpdateConnection(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteConnection(h.ctx, id)
+	},
+}
+
 // Foreign key
 // Get Connection Capacitiy
 // @Summary Get connection capacity
@@ -410,6 +486,13 @@ func (h *Handler) GetConnectionInterfaces(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfInterface, res, func(s godevmandb.Interface) int64 { return s.IfID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []iface{}
 	for _, s := range res {
 		r := iface{}
@@ -11,17 +11,29 @@ import (
 
 // Count Credentials
 // @Summary Count credentials
-// @Description Count number of credentials
+// @Description Count number of credentials matching filters
 // @Tags config
 // @ID count-credentials
+// @Param label_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/credentials/count [GET]
 func (h *Handler) CountCredentials(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountCredentials(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &credentialsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,84 +42,77 @@ func (h *Handler) CountCredentials(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of credentials
+var credentialsList = listSpec{
+	table: "credentials",
+	keys:  []string{"cred_id"},
+	model: godevmandb.Credential{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"label_f", "label", filterILike},
+	},
+}
+
 // List credentials
 // @Summary List credentials
-// @Description List credentials info
+// @Description List credentials info. Secrets are masked
 // @Tags config
 // @ID list-credentials
 // @Param label_f query string false "url encoded SQL 'ILIKE' operator pattern"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Credential
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/credentials [GET]
 func (h *Handler) GetCredentials(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetCredentialsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	v := r.FormValue("label_f")
-	if v != "" {
-		p.LabelF = v
+	// Query DB
+	l, err := parseListRequest(r, &credentialsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetCredentials(h.ctx, p)
+	res, page, err := listRows[godevmandb.Credential](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Decrypt secret
+	// Mask secret
 	for i, s := range res {
-		if s.EncSecret != "" {
-			val, err := godevmandb.DecryptStrAes(s.EncSecret, salt)
-			if err != nil {
-				RespondError(w, r, http.StatusInternalServerError, err.Error())
-				return
-			}
-
-			res[i].EncSecret = val
-		}
+		res[i].EncSecret = maskSecret(s.EncSecret)
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get Credential
 // @Summary Get credential
-// @Description Get credential info
+// @Description Get credential info. Secret is masked
 // @Tags config
 // @ID get-credential
 // @Param cred_id path string true "cred_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Credential
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid cred_id"
 // @Failure 404 {object} StatusResponse "Credential not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -131,9 +136,53 @@ func (h *Handler) GetCredential(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Mask secret. Encrypted secret is part of entity tag
+	setETagSource(w, res.EncSecret)
+	res.EncSecret = maskSecret(res.EncSecret)
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Reveal Credential
+// @Summary Reveal credential
+// @Description Get credential info with decrypted secret. Access is recorded in audit log
+// @Tags config
+// @ID reveal-credential
+// @Param cred_id path string true "cred_id"
+// @Success 200 {object} godevmandb.Credential
+// @Failure 400 {object} StatusResponse "Invalid cred_id"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Credential not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/credentials/{cred_id}/reveal [GET]
+func (h *Handler) RevealCredential(w http.ResponseWriter, r *http.Request) {
+	// Revealed secret can't be taken back if transaction is rolled back
+	if h.inTx() {
+		RespondError(w, r, http.StatusBadRequest, "Reveal is not allowed in batch")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "cred_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid credential ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetCredential(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Credential not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
 	// Decrypt secret
 	if res.EncSecret != "" {
-		val, err := godevmandb.DecryptStrAes(res.EncSecret, salt)
+		val, err := decryptSecret(res.EncSecret)
 		if err != nil {
 			RespondError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -142,6 +191,11 @@ func (h *Handler) GetCredential(w http.ResponseWriter, r *http.Request) {
 		res.EncSecret = val
 	}
 
+	if err := h.audit(r, "reveal", "credentials", id); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
@@ -168,7 +222,7 @@ func (h *Handler) CreateCredential(w http.ResponseWriter, r *http.Request) {
 
 	// Encrypt secret
 	if p.EncSecret != "" {
-		val, err := godevmandb.EncryptStrAes(p.EncSecret, salt)
+		val, err := encryptSecret(p.EncSecret)
 		if err != nil {
 			RespondError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -184,31 +238,26 @@ func (h *Handler) CreateCredential(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Decrypt secret
-	if res.EncSecret != "" {
-		val, err := godevmandb.DecryptStrAes(res.EncSecret, salt)
-		if err != nil {
-			RespondError(w, r, http.StatusInternalServerError, err.Error())
-			return
-		}
-
-		res.EncSecret = val
-	}
+	// Mask secret
+	res.EncSecret = maskSecret(res.EncSecret)
 
 	RespondJSON(w, r, http.StatusCreated, res)
 }
 
 // Update Credential
 // @Summary Update credential
-// @Description Update credential
+// @Description Update credential. Masked secret value keeps existing secret
 // @Tags config
 // @ID update-credential
 // @Param cred_id path string true "cred_id"
 // @Param Body body godevmandb.UpdateCredentialParams true "JSON object of godevmandb.UpdateCredentialParams.<br />Ignored fields:<ul><li>cred_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Credential
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
-// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 404 {object} StatusResponse "Credential not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/credentials/{cred_id} [PUT]
 func (h *Handler) UpdateCredential(w http.ResponseWriter, r *http.Request) {
@@ -228,9 +277,23 @@ func (h *Handler) UpdateCredential(w http.ResponseWriter, r *http.Request) {
 
 	p.CredID = id
 
-	// Encrypt secret
-	if p.EncSecret != "" {
-		val, err := godevmandb.EncryptStrAes(p.EncSecret, salt)
+	q := godevmandb.New(h.db)
+
+	// Encrypt secret. Keep existing secret if masked value is sent back
+	if p.EncSecret == secretMask {
+		cur, err := q.GetCredential(h.ctx, id)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				RespondError(w, r, http.StatusNotFound, "Credential not found")
+			} else {
+				RespondError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		p.EncSecret = cur.EncSecret
+	} else if p.EncSecret != "" {
+		val, err := encryptSecret(p.EncSecret)
 		if err != nil {
 			RespondError(w, r, http.StatusInternalServerError, err.Error())
 			return
@@ -239,38 +302,56 @@ func (h *Handler) UpdateCredential(w http.ResponseWriter, r *http.Request) {
 		p.EncSecret = val
 	}
 
-	q := godevmandb.New(h.db)
 	res, err := q.UpdateCredential(h.ctx, p)
-
 	if err != nil {
-		RespondError(w, r, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Decrypt secret
-	if res.EncSecret != "" {
-		val, err := godevmandb.DecryptStrAes(res.EncSecret, salt)
-		if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Credential not found")
+		} else {
 			RespondError(w, r, http.StatusInternalServerError, err.Error())
-			return
 		}
-
-		res.EncSecret = val
+		return
 	}
 
+	// Mask secret. Encrypted secret is part of entity tag
+	setETagSource(w, res.EncSecret)
+	res.EncSecret = maskSecret(res.EncSecret)
+
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Credential
+// @Summary Patch credential
+// @Description Partially update credential with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags config
+// @ID patch-credential
+// @Param cred_id path string true "cred_id"
+// @Param Body body godevmandb.UpdateCredentialParams true "JSON merge patch of godevmandb.UpdateCredentialParams.<br />Ignored fields:<ul><li>cred_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Credential
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/credentials/{cred_id} [PATCH]
+func (h *Handler) PatchCredential(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetCredential, h.UpdateCredential)
+}
+
 // Delete Credential
 // @Summary Delete credential
 // @Description Delete credential
 // @Tags config
 // @ID delete-credential
 // @Param cred_id path string true "cred_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid cred_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/credentials/{cred_id} [DELETE]
 func (h *Handler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/httplog"
+)
+
+// Media type of CSV response
+const csvType = "text/csv"
+
+// Check if CSV response is requested by "format" query parameter or by Accept header
+func wantCSV(r *http.Request) bool {
+	if v := r.FormValue("format"); v != "" {
+		return v == "csv"
+	}
+
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err == nil && mt == csvType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSON field names of struct type in field order. Fields of embedded structs are included
+func jsonFields(t reflect.Type) []string {
+	res := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				res = append(res, jsonFields(ft)...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = f.Name
+		}
+		res = append(res, tag)
+	}
+
+	return res
+}
+
+// CSV cell of JSON value. Null is empty cell, objects and arrays are JSON encoded.
+// Strings which spreadsheet applications would evaluate as formulas are prefixed with "'"
+func csvCell(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if t != "" && strings.ContainsRune("=+-@\t\r", rune(t[0])) {
+			return "'" + t
+		}
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// Check if payload is list of objects which can be returned as CSV
+func isList(payload interface{}) bool {
+	t := reflect.TypeOf(payload)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return false
+	}
+
+	e := t.Elem()
+	if e.Kind() == reflect.Pointer {
+		e = e.Elem()
+	}
+
+	switch e.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return true
+	}
+
+	return false
+}
+
+// JSON object of list element with values decoded as in JSON response
+func csvRow(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]any{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	err = d.Decode(&res)
+
+	return res, err
+}
+
+// CSV response of slice of objects. First row contains JSON field names of objects.
+// Values are formatted as in JSON response
+func respondCSV(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	if payload != nil && !isList(payload) {
+		RespondError(w, r, http.StatusInternalServerError, "CSV conversion failed")
+		return
+	}
+
+	v := reflect.ValueOf(payload)
+	n := 0
+	if v.IsValid() {
+		n = v.Len()
+	}
+
+	// Header from element type or keys of first row
+	var fields []string
+	if v.IsValid() {
+		e := v.Type().Elem()
+		if e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		if e.Kind() == reflect.Struct {
+			fields = jsonFields(e)
+		}
+	}
+	if fields == nil && n > 0 {
+		row, err := csvRow(v.Index(0).Interface())
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, "CSV conversion failed")
+			return
+		}
+		for k := range row {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+	}
+
+	w.Header().Set("Content-Type", csvType+"; charset=utf-8")
+	w.WriteHeader(code)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(fields)
+	rec := make([]string, len(fields))
+	for i := 0; i < n; i++ {
+		row, err := csvRow(v.Index(i).Interface())
+		if err != nil {
+			// Status is already sent
+			hlog := httplog.LogEntry(r.Context())
+			hlog.Error().Msg("CSV conversion failed: " + err.Error())
+			break
+		}
+
+		for j, f := range fields {
+			rec[j] = csvCell(row[f])
+		}
+		_ = cw.Write(rec)
+	}
+	cw.Flush()
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCsvCell(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"null", nil, ""},
+		{"empty string", "", ""},
+		{"string", "core-router", "core-router"},
+		{"formula", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"plus", "+1", "'+1"},
+		{"minus", "-cmd", "'-cmd"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"carriage return", "\r=1", "'\r=1"},
+		{"inner equal sign", "a=b", "a=b"},
+		{"negative number", json.Number("-5"), "-5"},
+		{"bool", true, "true"},
+		{"object", map[string]any{"a": "=b"}, `{"a":"=b"}`},
+		{"array", []any{json.Number("1"), "x"}, `[1,"x"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvCell(tt.in); got != tt.want {
+				t.Errorf("csvCell(%#v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
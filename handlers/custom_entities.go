@@ -11,17 +11,32 @@ import (
 
 // Count CustomEntities
 // @Summary Count custom_entities
-// @Description Count number of custom_entities
+// @Description Count number of custom_entities matching filters
 // @Tags entities
 // @ID count-custom_entities
+// @Param serial_nr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param manufacturer_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param part_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/custom_entities/count [GET]
 func (h *Handler) CountCustomEntities(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountCustomEntities(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &customEntitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +45,23 @@ func (h *Handler) CountCustomEntities(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of custom entities
+var customEntitiesList = listSpec{
+	table: "custom_entities",
+	keys:  []string{"cent_id"},
+	model: godevmandb.CustomEntity{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"manufacturer_f", "manufacturer", filterILikeEmpty},
+		{"serial_nr_f", "serial_nr", filterILikeEmpty},
+		{"part_f", "part", filterILikeNull},
+		{"descr_f", "descr", filterILikeNull},
+	},
+}
+
 // List custom_entities
 // @Summary List custom_entities
 // @Description List custom_entities info
@@ -40,66 +72,39 @@ func (h *Handler) CountCustomEntities(w http.ResponseWriter, r *http.Request) {
 // @Param part_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param descr_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.CustomEntity
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/custom_entities [GET]
 func (h *Handler) GetCustomEntities(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetCustomEntitiesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("serial_nr_f"); v != "" {
-		p.SerialNrF = v
-	}
-
-	if v := r.FormValue("manufacturer_f"); v != "" {
-		p.ManufacturerF = v
-	}
-
-	if v := r.FormValue("part_f"); v != "" {
-		p.PartF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
+	// Query DB
+	l, err := parseListRequest(r, &customEntitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetCustomEntities(h.ctx, p)
+	res, page, err := listRows[godevmandb.CustomEntity](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get CustomEntity
@@ -108,7 +113,10 @@ func (h *Handler) GetCustomEntities(w http.ResponseWriter, r *http.Request) {
 // @Tags entities
 // @ID get-customEntity
 // @Param cent_id path string true "cent_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.CustomEntity
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid cent_id"
 // @Failure 404 {object} StatusResponse "CustomEntity not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -173,10 +181,13 @@ func (h *Handler) CreateCustomEntity(w http.ResponseWriter, r *http.Request) {
 // @ID update-customEntity
 // @Param cent_id path string true "cent_id"
 // @Param Body body godevmandb.UpdateCustomEntityParams true "JSON object of godevmandb.UpdateCustomEntityParams.<br />Ignored fields:<ul><li>cent_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.CustomEntity
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/custom_entities/{cent_id} [PUT]
 func (h *Handler) UpdateCustomEntity(w http.ResponseWriter, r *http.Request) {
@@ -206,16 +217,39 @@ func (h *Handler) UpdateCustomEntity(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch CustomEntity
+// @Summary Patch customEntity
+// @Description Partially update customEntity with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags entities
+// @ID patch-customEntity
+// @Param cent_id path string true "cent_id"
+// @Param Body body godevmandb.UpdateCustomEntityParams true "JSON merge patch of godevmandb.UpdateCustomEntityParams.<br />Ignored fields:<ul><li>cent_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.CustomEntity
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/custom_entities/{cent_id} [PATCH]
+func (h *Handler) PatchCustomEntity(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetCustomEntity, h.UpdateCustomEntity)
+}
+
 // Delete CustomEntity
 // @Summary Delete customEntity
 // @Description Delete customEntity
 // @Tags entities
 // @ID delete-customEntity
 // @Param cent_id path string true "cent_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid cent_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/custom_entities/{cent_id} [DELETE]
 func (h *Handler) DeleteCustomEntity(w http.ResponseWriter, r *http.Request) {
@@ -234,3 +268,35 @@ func (h *Handler) DeleteCustomEntity(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Bulk operations of custom entities
+var customEntitiesBulk = bulkSpec{
+	scope: ScopeShared,
+	audit: AuditCustomEntity,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateCustomEntityParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateCustomEntity(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.CentID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateCustomEntityParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.CentID = id
+
+		return q.UpdateCustomEntity(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteCustomEntity(h.ctx, id)
+	},
+}
@@ -11,17 +11,29 @@ import (
 
 // Count DeviceDomains
 // @Summary Count device_domains
-// @Description Count number of device domains
+// @Description Count number of device domains matching filters
 // @Tags devices
 // @ID count-device_domains
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/domains/count [GET]
 func (h *Handler) CountDeviceDomains(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountDeviceDomains(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &deviceDomainsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +42,21 @@ func (h *Handler) CountDeviceDomains(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of device domains
+var deviceDomainsList = listSpec{
+	table:  "device_domains",
+	keys:   []string{"dom_id"},
+	domain: domainOfDevice,
+	model:  godevmandb.DeviceDomain{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILike},
+	},
+}
+
 // List device_domains
 // @Summary List device_domains
 // @Description List device domains info
@@ -37,54 +64,39 @@ func (h *Handler) CountDeviceDomains(w http.ResponseWriter, r *http.Request) {
 // @ID list-device_domains
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.DeviceDomain
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/domains [GET]
 func (h *Handler) GetDeviceDomains(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetDeviceDomainsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
+	// Query DB
+	l, err := parseListRequest(r, &deviceDomainsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetDeviceDomains(h.ctx, p)
+	res, page, err := listRows[godevmandb.DeviceDomain](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get DeviceDomain
@@ -93,7 +105,10 @@ func (h *Handler) GetDeviceDomains(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-device_domain
 // @Param dom_id path string true "dom_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.DeviceDomain
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid dom_id"
 // @Failure 404 {object} StatusResponse "Domain not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -159,10 +174,13 @@ func (h *Handler) CreateDeviceDomain(w http.ResponseWriter, r *http.Request) {
 // @ID update-device_domain
 // @Param dom_id path string true "dom_id"
 // @Param Body body godevmandb.UpdateDeviceDomainParams true "JSON object of godevmandb.UpdateDeviceDomainParams.<br />Ignored fields:<ul><li>dom_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.DeviceDomain
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/domains/{dom_id} [PUT]
 func (h *Handler) UpdateDeviceDomain(w http.ResponseWriter, r *http.Request) {
@@ -193,16 +211,39 @@ func (h *Handler) UpdateDeviceDomain(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch DeviceDomain
+// @Summary Patch device_domain
+// @Description Partially update device_domain with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device_domain
+// @Param dom_id path string true "dom_id"
+// @Param Body body godevmandb.UpdateDeviceDomainParams true "JSON merge patch of godevmandb.UpdateDeviceDomainParams.<br />Ignored fields:<ul><li>dom_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceDomain
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/domains/{dom_id} [PATCH]
+func (h *Handler) PatchDeviceDomain(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDeviceDomain, h.UpdateDeviceDomain)
+}
+
 // Delete DeviceDomain
 // @Summary Delete device_domain
 // @Description Delete device domain
 // @Tags devices
 // @ID delete-device_domain
 // @Param dom_id path string true "dom_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid dom_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/domains/{dom_id} [DELETE]
 func (h *Handler) DeleteDeviceDomain(w http.ResponseWriter, r *http.Request) {
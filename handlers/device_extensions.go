@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// Count DeviceExtensions
+// @Summary Count device_extensions
+// @Description Count number of device_extensions matching filters
+// @Tags devices
+// @ID count-device_extensions
+// @Param field_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param content_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/count [GET]
+func (h *Handler) CountDeviceExtensions(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &deviceExtensionsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List query specification of device extensions
+var deviceExtensionsList = listSpec{
+	table:  "device_extensions",
+	keys:   []string{"ext_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.DeviceExtension{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"field_f", "field", filterILikeEmpty},
+		{"content_f", "content", filterILikeNull},
+	},
+}
+
+// List device_extensions
+// @Summary List device_extensions
+// @Description List device_extensions info
+// @Tags devices
+// @ID list-device_extensions
+// @Param field_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param content_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} godevmandb.DeviceExtension
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions [GET]
+func (h *Handler) GetDeviceExtensions(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &deviceExtensionsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[godevmandb.DeviceExtension](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get DeviceExtension
+// @Summary Get device_extension
+// @Description Get device_extension info
+// @Tags devices
+// @ID get-device_extension
+// @Param ext_id path string true "ext_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} godevmandb.DeviceExtension
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid ext_id"
+// @Failure 404 {object} StatusResponse "DeviceExtension not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/{ext_id} [GET]
+func (h *Handler) GetDeviceExtension(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ext_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device extension ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetDeviceExtension(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Extension not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Create DeviceExtension
+// @Summary Create device_extension
+// @Description Create device_extension. Field must be unique per device
+// @Tags devices
+// @ID create-device_extension
+// @Param Body body godevmandb.CreateDeviceExtensionParams true "JSON object of godevmandb.CreateDeviceExtensionParams"
+// @Success 201 {object} godevmandb.DeviceExtension
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions [POST]
+func (h *Handler) CreateDeviceExtension(w http.ResponseWriter, r *http.Request) {
+	var p godevmandb.CreateDeviceExtensionParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	q := godevmandb.New(h.db)
+	res, err := q.CreateDeviceExtension(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, res)
+}
+
+// Update DeviceExtension
+// @Summary Update device_extension
+// @Description Update device_extension
+// @Tags devices
+// @ID update-device_extension
+// @Param ext_id path string true "ext_id"
+// @Param Body body godevmandb.UpdateDeviceExtensionParams true "JSON object of godevmandb.UpdateDeviceExtensionParams.<br />Ignored fields:<ul><li>ext_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceExtension
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/{ext_id} [PUT]
+func (h *Handler) UpdateDeviceExtension(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ext_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device extension ID")
+		return
+	}
+
+	var p godevmandb.UpdateDeviceExtensionParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	p.ExtID = id
+
+	q := godevmandb.New(h.db)
+	res, err := q.UpdateDeviceExtension(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Patch DeviceExtension
+// @Summary Patch device_extension
+// @Description Partially update device_extension with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device_extension
+// @Param ext_id path string true "ext_id"
+// @Param Body body godevmandb.UpdateDeviceExtensionParams true "JSON merge patch of godevmandb.UpdateDeviceExtensionParams.<br />Ignored fields:<ul><li>ext_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceExtension
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/{ext_id} [PATCH]
+func (h *Handler) PatchDeviceExtension(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDeviceExtension, h.UpdateDeviceExtension)
+}
+
+// Delete DeviceExtension
+// @Summary Delete device_extension
+// @Description Delete device_extension
+// @Tags devices
+// @ID delete-device_extension
+// @Param ext_id path string true "ext_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid ext_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/{ext_id} [DELETE]
+func (h *Handler) DeleteDeviceExtension(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ext_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device extension ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	err = q.DeleteDeviceExtension(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Foreign key
+// Get DeviceExtension Device
+// @Summary Get device_extension device
+// @Description Get device_extension device info
+// @Tags devices
+// @ID get-device_extension-device
+// @Param ext_id path string true "ext_id"
+// @Success 200 {object} device
+// @Failure 400 {object} StatusResponse "Invalid ext_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/extensions/{ext_id}/device [GET]
+func (h *Handler) GetDeviceExtensionDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ext_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device extension ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetDeviceExtensionDevice(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := device{}
+	out.getValues(res)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
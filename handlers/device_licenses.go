@@ -11,17 +11,39 @@ import (
 
 // Count DeviceLicenses
 // @Summary Count device_licenses
-// @Description Count number of device_licenses
+// @Description Count number of device_licenses matching filters
 // @Tags devices
 // @ID count-device_licenses
+// @Param product_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param condition_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param installed_le query int false "SQL '<=' operator value"
+// @Param installed_ge query int false "SQL '>=' operator value"
+// @Param unlocked_le query int false "SQL '<=' operator value"
+// @Param unlocked_ge query int false "SQL '>=' operator value"
+// @Param tot_inst_le query int false "SQL '<=' operator value"
+// @Param tot_inst_ge query int false "SQL '>=' operator value"
+// @Param used_le query int false "SQL '<=' operator value"
+// @Param used_ge query int false "SQL '>=' operator value"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/licenses/count [GET]
 func (h *Handler) CountDeviceLicenses(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountDeviceLicenses(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &deviceLicensesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +52,31 @@ func (h *Handler) CountDeviceLicenses(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of device licenses
+var deviceLicensesList = listSpec{
+	table:  "device_licenses",
+	keys:   []string{"lic_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.DeviceLicense{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"installed_le", "installed", filterLeNull},
+		{"installed_ge", "installed", filterGeNull},
+		{"unlocked_le", "unlocked", filterLeNull},
+		{"unlocked_ge", "unlocked", filterGeNull},
+		{"tot_inst_le", "tot_inst", filterLeNull},
+		{"tot_inst_ge", "tot_inst", filterGeNull},
+		{"used_le", "used", filterLeNull},
+		{"used_ge", "used", filterGeNull},
+		{"product_f", "product", filterILikeNull},
+		{"descr_f", "descr", filterILikeNull},
+		{"condition_f", "condition", filterILikeNull},
+	},
+}
+
 // List device_licenses
 // @Summary List device_licenses
 // @Description List device_licenses info
@@ -47,94 +94,39 @@ func (h *Handler) CountDeviceLicenses(w http.ResponseWriter, r *http.Request) {
 // @Param used_le query int false "SQL '<=' operator value"
 // @Param used_ge query int false "SQL '>=' operator value"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.DeviceLicense
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/licenses [GET]
 func (h *Handler) GetDeviceLicenses(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetDeviceLicensesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("product_f"); v != "" {
-		p.ProductF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
-	}
-
-	if v := r.FormValue("condition_f"); v != "" {
-		p.ConditionF = &v
-	}
-
-	if v := r.FormValue("installed_le"); v != "" {
-		p.InstalledLe = &v
-	}
-
-	if v := r.FormValue("installed_ge"); v != "" {
-		p.InstalledGe = &v
-	}
-
-	if v := r.FormValue("unlocked_le"); v != "" {
-		p.UnlockedLe = &v
-	}
-
-	if v := r.FormValue("unlocked_ge"); v != "" {
-		p.UnlockedGe = &v
-	}
-
-	if v := r.FormValue("tot_inst_le"); v != "" {
-		p.TotInstLe = &v
-	}
-
-	if v := r.FormValue("tot_inst_ge"); v != "" {
-		p.TotInstGe = &v
-	}
-
-	if v := r.FormValue("used_le"); v != "" {
-		p.UsedLe = &v
-	}
-
-	if v := r.FormValue("used_ge"); v != "" {
-		p.UsedGe = &v
+	// Query DB
+	l, err := parseListRequest(r, &deviceLicensesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetDeviceLicenses(h.ctx, p)
+	res, page, err := listRows[godevmandb.DeviceLicense](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get DeviceLicense
@@ -143,7 +135,10 @@ func (h *Handler) GetDeviceLicenses(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-device_license
 // @Param lic_id path string true "lic_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.DeviceLicense
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid lic_id"
 // @Failure 404 {object} StatusResponse "DeviceLicense not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -209,10 +204,13 @@ func (h *Handler) CreateDeviceLicense(w http.ResponseWriter, r *http.Request) {
 // @ID update-device_license
 // @Param lic_id path string true "lic_id"
 // @Param Body body godevmandb.UpdateDeviceLicenseParams true "JSON object of godevmandb.UpdateDeviceLicenseParams.<br />Ignored fields:<ul><li>lic_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.DeviceLicense
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/licenses/{lic_id} [PUT]
 func (h *Handler) UpdateDeviceLicense(w http.ResponseWriter, r *http.Request) {
@@ -243,16 +241,39 @@ func (h *Handler) UpdateDeviceLicense(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch DeviceLicense
+// @Summary Patch device_license
+// @Description Partially update device_license with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device_license
+// @Param lic_id path string true "lic_id"
+// @Param Body body godevmandb.UpdateDeviceLicenseParams true "JSON merge patch of godevmandb.UpdateDeviceLicenseParams.<br />Ignored fields:<ul><li>lic_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceLicense
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failde DB transaction"
+// @Router /devices/licenses/{lic_id} [PATCH]
+func (h *Handler) PatchDeviceLicense(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDeviceLicense, h.UpdateDeviceLicense)
+}
+
 // Delete DeviceLicense
 // @Summary Delete device_license
 // @Description Delete device_license
 // @Tags devices
 // @ID delete-device_license
 // @Param lic_id path string true "lic_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid lic_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/licenses/{lic_id} [DELETE]
 func (h *Handler) DeleteDeviceLicense(w http.ResponseWriter, r *http.Request) {
@@ -272,6 +293,38 @@ func (h *Handler) DeleteDeviceLicense(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of device licenses
+var deviceLicensesBulk = bulkSpec{
+	scope: ScopeDeviceLicense,
+	audit: AuditDeviceLicense,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateDeviceLicenseParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateDeviceLicense(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.LicID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateDeviceLicenseParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.LicID = id
+
+		return q.UpdateDeviceLicense(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteDeviceLicense(h.ctx, id)
+	},
+}
+
 // Foreign key
 // Get DeviceLicense Device
 // @Summary Get device_license device
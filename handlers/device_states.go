@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// SQL expression of time since device is down. NULL if device is up
+const stateDownSince = `CASE WHEN t.up_time IS NULL OR t.down_time > t.up_time THEN t.down_time END`
+
+// Input parameters of device reachability report
+type reachabilityParams struct {
+	Reachable *bool  `json:"reachable"`
+	Method    string `json:"method"`
+}
+
+// Device state after reachability report
+type reachabilityResult struct {
+	State godevmandb.DeviceState `json:"state"`
+	// True if device changed from up to down or from down to up
+	Changed bool `json:"changed"`
+}
+
+// Count DeviceStates
+// @Summary Count device_states
+// @Description Count number of device_states matching filters
+// @Tags devices
+// @ID count-device_states
+// @Param method_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param down_since_ge query int false "device is down and down time >= (unix timestamp in milliseconds)"
+// @Param down_since_le query int false "device is down and down time <= (unix timestamp in milliseconds)"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/count [GET]
+func (h *Handler) CountDeviceStates(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &deviceStatesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List query specification of device states
+var deviceStatesList = listSpec{
+	table:  "device_states",
+	keys:   []string{"dev_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.DeviceState{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"method_f", "method", filterILikeEmpty},
+		{"down_since_ge", stateDownSince, filterTimeGe},
+		{"down_since_le", stateDownSince, filterTimeLe},
+	},
+}
+
+// List device_states
+// @Summary List device_states
+// @Description List device_states info
+// @Tags devices
+// @ID list-device_states
+// @Param method_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param down_since_ge query int false "device is down and down time >= (unix timestamp in milliseconds)"
+// @Param down_since_le query int false "device is down and down time <= (unix timestamp in milliseconds)"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} godevmandb.DeviceState
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states [GET]
+func (h *Handler) GetDeviceStates(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &deviceStatesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[godevmandb.DeviceState](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get DeviceState
+// @Summary Get device_state
+// @Description Get device_state info
+// @Tags devices
+// @ID get-device_state
+// @Param dev_id path string true "dev_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} godevmandb.DeviceState
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid dev_id"
+// @Failure 404 {object} StatusResponse "DeviceState not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id} [GET]
+func (h *Handler) GetDeviceState(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetDeviceState(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "State not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Create DeviceState
+// @Summary Create device_state
+// @Description Create device_state
+// @Tags devices
+// @ID create-device_state
+// @Param Body body godevmandb.CreateDeviceStateParams true "JSON object of godevmandb.CreateDeviceStateParams"
+// @Success 201 {object} godevmandb.DeviceState
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states [POST]
+func (h *Handler) CreateDeviceState(w http.ResponseWriter, r *http.Request) {
+	var p godevmandb.CreateDeviceStateParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	q := godevmandb.New(h.db)
+	res, err := q.CreateDeviceState(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, res)
+}
+
+// Update DeviceState
+// @Summary Update device_state
+// @Description Update device_state
+// @Tags devices
+// @ID update-device_state
+// @Param dev_id path string true "dev_id"
+// @Param Body body godevmandb.UpdateDeviceStateParams true "JSON object of godevmandb.UpdateDeviceStateParams.<br />Ignored fields:<ul><li>dev_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceState
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id} [PUT]
+func (h *Handler) UpdateDeviceState(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	var p godevmandb.UpdateDeviceStateParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	p.DevID = id
+
+	q := godevmandb.New(h.db)
+	res, err := q.UpdateDeviceState(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Patch DeviceState
+// @Summary Patch device_state
+// @Description Partially update device_state with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device_state
+// @Param dev_id path string true "dev_id"
+// @Param Body body godevmandb.UpdateDeviceStateParams true "JSON merge patch of godevmandb.UpdateDeviceStateParams.<br />Ignored fields:<ul><li>dev_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceState
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id} [PATCH]
+func (h *Handler) PatchDeviceState(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDeviceState, h.UpdateDeviceState)
+}
+
+// Delete DeviceState
+// @Summary Delete device_state
+// @Description Delete device_state
+// @Tags devices
+// @ID delete-device_state
+// @Param dev_id path string true "dev_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid dev_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id} [DELETE]
+func (h *Handler) DeleteDeviceState(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	err = q.DeleteDeviceState(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Report DeviceState Reachability
+// @Summary Report device reachability
+// @Description Report result of device reachability check. Sets up_time or down_time to current time
+// @Description if device changed from down to up or from up to down and sets device unresponsive flag.
+// @Description State and device are updated in one transaction. State is created if it does not exist.
+// @Tags devices
+// @ID report-device_state-reachability
+// @Param dev_id path string true "dev_id"
+// @Param Body body reachabilityParams true "JSON object of reachabilityParams. Empty method keeps previous value"
+// @Success 200 {object} reachabilityResult
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Device not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id}/reachability [PUT]
+func (h *Handler) ReportDeviceReachability(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	var p reachabilityParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil || p.Reachable == nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	tx, err := h.db.Begin(h.ctx)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(h.ctx)
+
+	// Lock device
+	var unresponsive bool
+	err = tx.QueryRow(h.ctx, `SELECT unresponsive FROM devices WHERE dev_id = $1 FOR UPDATE`, id).Scan(&unresponsive)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Device not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	q := godevmandb.New(h.db).WithTx(tx)
+	now := time.Now()
+	out := reachabilityResult{}
+
+	cur, err := q.GetDeviceState(h.ctx, id)
+	if err != nil && err.Error() != "no rows in result set" {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err != nil {
+		c := godevmandb.CreateDeviceStateParams{DevID: id, Method: p.Method}
+		if *p.Reachable {
+			c.UpTime = &now
+		} else {
+			c.DownTime = &now
+		}
+
+		out.Changed = true
+		out.State, err = q.CreateDeviceState(h.ctx, c)
+	} else {
+		u := godevmandb.UpdateDeviceStateParams{
+			DevID:    id,
+			UpTime:   cur.UpTime,
+			DownTime: cur.DownTime,
+			Method:   cur.Method,
+		}
+		if p.Method != "" {
+			u.Method = p.Method
+		}
+
+		down := cur.UpTime == nil || (cur.DownTime != nil && cur.DownTime.After(*cur.UpTime))
+		switch {
+		case *p.Reachable && down:
+			u.UpTime = &now
+			out.Changed = true
+		case !*p.Reachable && !down:
+			u.DownTime = &now
+			out.Changed = true
+		}
+
+		out.State = cur
+		if out.Changed || u.Method != cur.Method {
+			out.State, err = q.UpdateDeviceState(h.ctx, u)
+		}
+	}
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// State change is recorded by audit middleware, device change is recorded here
+	if unresponsive == *p.Reachable {
+		_, err = h.txAudited(tx, r, AuditDevice, "update", id, func() (int64, error) {
+			_, err := tx.Exec(h.ctx, `UPDATE devices SET unresponsive = $2 WHERE dev_id = $1`, id, !*p.Reachable)
+			return id, err
+		})
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(h.ctx); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Foreign key
+// Get DeviceState Device
+// @Summary Get device_state device
+// @Description Get device_state device info
+// @Tags devices
+// @ID get-device_state-device
+// @Param dev_id path string true "dev_id"
+// @Success 200 {object} device
+// @Failure 400 {object} StatusResponse "Invalid dev_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/states/{dev_id}/device [GET]
+func (h *Handler) GetDeviceStateDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetDeviceStateDevice(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := device{}
+	out.getValues(res)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
@@ -0,0 +1,914 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+)
+
+// Error of inconsistent device snapshot
+var errSnapshot = errors.New("Invalid snapshot")
+
+// Error of snapshot row referring rows not accessible by user
+var errSyncDenied = errors.New("Access denied")
+
+// Snapshot of device child collections. Omitted collection is not reconciled.
+// Rows are JSON objects of corresponding resource without ID and dev_id fields.
+// Subinterfaces and xconnects refer to interface by if_descr field instead of if_id.
+// Entities refer to parent entity by parent_snmp_ent_id field instead of parent_ent_id.
+// Interfaces refer to parent interface by parent_descr field instead of parent
+// and to entity by ent_snmp_ent_id field instead of ent_id
+type deviceSnapshot struct {
+	Entities      []map[string]any `json:"entities"`
+	Interfaces    []map[string]any `json:"interfaces"`
+	Subinterfaces []map[string]any `json:"subinterfaces"`
+	IpInterfaces  []map[string]any `json:"ip_interfaces"`
+	Vlans         []map[string]any `json:"vlans"`
+	Xconnects     []map[string]any `json:"xconnects"`
+	OspfNbrs      []map[string]any `json:"ospf_nbrs"`
+	RlNbrs        []map[string]any `json:"rl_nbrs"`
+}
+
+// Summary of changes in device child collection
+type syncSummary struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Archived  int `json:"archived"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Result of device sync. Changes are summarized by reconciled collection
+type deviceSyncResult struct {
+	DevID   int64                   `json:"dev_id"`
+	Changes map[string]*syncSummary `json:"changes"`
+}
+
+// Reconciliation specification of device child collection. T is API representation of row
+type syncSpec[T any] struct {
+	audit   AuditScope
+	scope   DomainScope // authorizes rows referred by snapshot row
+	id      func(T) int64
+	key     func(T) string             // natural key of row
+	state   func(T) any                // comparable state of row
+	prepare func(map[string]any) error // resolves references of snapshot row
+	create  func(T) (int64, error)     // returns ID of created row
+	update  func(int64, T) error       // updates row with ID
+	delete  func(int64) error          // deletes row with ID
+	archive func(T) (int64, error)     // archives row before delete. Returns ID of archived row
+	arcAud  AuditScope                 // audit scope of archived rows
+	ids     map[string]int64           // IDs of snapshot rows by natural key. Filled during reconciliation
+}
+
+// Natural key of row from its values
+func syncKey(v ...any) string {
+	parts := make([]string, 0, len(v))
+	for _, x := range v {
+		rv := reflect.ValueOf(x)
+		if rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				parts = append(parts, "")
+				continue
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() {
+			parts = append(parts, "")
+			continue
+		}
+		parts = append(parts, fmt.Sprint(rv.Interface()))
+	}
+
+	return strings.Join(parts, "/")
+}
+
+// Normalized inet value as natural key
+func inetKey(p *string) string {
+	if v := pgInetToPtr(strToPgInet(p)); v != nil {
+		return *v
+	}
+
+	return ""
+}
+
+// Convert JSON value to row type
+func syncDecode[T any](v any) (T, error) {
+	var res T
+	b, err := json.Marshal(v)
+	if err != nil {
+		return res, err
+	}
+
+	d := json.NewDecoder(strings.NewReader(string(b)))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&res); err != nil {
+		return res, fmt.Errorf("%w: %s", errSnapshot, err.Error())
+	}
+
+	return res, nil
+}
+
+// Reconcile snapshot rows with current rows of collection.
+// Matched rows are updated with fields present in snapshot row, unmatched snapshot rows are created.
+// Returns current rows missing from snapshot
+func syncRows[T any](h *Handler, tx pgx.Tx, r *http.Request, s syncSpec[T], cur []T, items []map[string]any, sum *syncSummary) ([]T, error) {
+	// Authorize through transaction to see rows created by sync
+	th := h.WithTx(tx)
+
+	byKey := make(map[string]T, len(cur))
+	for _, c := range cur {
+		byKey[s.key(c)] = c
+	}
+
+	for _, it := range items {
+		if s.prepare != nil {
+			if err := s.prepare(it); err != nil {
+				return nil, err
+			}
+		}
+
+		n, err := syncDecode[T](it)
+		if err != nil {
+			return nil, err
+		}
+
+		k := s.key(n)
+		if _, ok := s.ids[k]; ok {
+			return nil, fmt.Errorf("%w: duplicate %s row %q", errSnapshot, s.audit.table, k)
+		}
+
+		c, ok := byKey[k]
+		if !ok {
+			if err := syncAuthz(th, r, s.scope, it); err != nil {
+				return nil, err
+			}
+
+			id, err := h.txAudited(tx, r, s.audit, "create", 0, func() (int64, error) {
+				return s.create(n)
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			s.ids[k] = id
+			sum.Created++
+			continue
+		}
+
+		// Fields missing from snapshot row keep their current values
+		b, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		cv, err := decodeJSONValue(b)
+		if err != nil {
+			return nil, err
+		}
+		m, err := syncDecode[T](mergePatchValue(cv, it))
+		if err != nil {
+			return nil, err
+		}
+
+		id := s.id(c)
+		s.ids[k] = id
+		if reflect.DeepEqual(s.state(c), s.state(m)) {
+			sum.Unchanged++
+			continue
+		}
+
+		if err := syncAuthz(th, r, s.scope, it); err != nil {
+			return nil, err
+		}
+
+		_, err = h.txAudited(tx, r, s.audit, "update", id, func() (int64, error) {
+			return id, s.update(id, m)
+		})
+		if err != nil {
+			return nil, err
+		}
+		sum.Updated++
+	}
+
+	res := make([]T, 0)
+	for _, c := range cur {
+		if _, ok := s.ids[s.key(c)]; !ok {
+			res = append(res, c)
+		}
+	}
+
+	return res, nil
+}
+
+// Authorize rows referred by snapshot row in device domain scope of collection
+func syncAuthz(h *Handler, r *http.Request, s DomainScope, it map[string]any) error {
+	u := requestUser(r)
+	if u == nil {
+		return errSyncDenied
+	}
+
+	b, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	var f map[string]json.RawMessage
+	if err := json.Unmarshal(b, &f); err != nil {
+		return err
+	}
+
+	ok, err := h.authzRefs(u, s, f)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: snapshot row refers to row not accessible by user", errSyncDenied)
+	}
+
+	return nil
+}
+
+// Remove rows missing from snapshot. Rows are archived first if archive is set.
+// Rows already removed by cascade are skipped
+func syncRemove[T any](h *Handler, tx pgx.Tx, r *http.Request, s syncSpec[T], rows []T, archive bool, sum *syncSummary) error {
+	for _, c := range rows {
+		id := s.id(c)
+		if archive && s.archive != nil {
+			if before, err := h.auditRow(tx, s.audit, []string{strconv.FormatInt(id, 10)}); err != nil {
+				return err
+			} else if before == nil {
+				continue
+			}
+
+			_, err := h.txAudited(tx, r, s.arcAud, "create", 0, func() (int64, error) {
+				return s.archive(c)
+			})
+			if err != nil {
+				return err
+			}
+			sum.Archived++
+		}
+
+		_, err := h.txAudited(tx, r, s.audit, "delete", id, func() (int64, error) {
+			return id, s.delete(id)
+		})
+		if err != nil {
+			if errors.Is(err, errRowNotFound) {
+				continue
+			}
+			return err
+		}
+		sum.Deleted++
+	}
+
+	return nil
+}
+
+// Natural key of xconnect. Xconnect without vc_idx is matched by vc_id and peer_ip
+func xconnectKey(s xconnect) string {
+	if s.VcIdx == 0 {
+		return syncKey(nil, s.VcID, inetKey(s.PeerIp))
+	}
+
+	return syncKey(s.VcIdx)
+}
+
+// Delete interface with its child interfaces, subinterfaces and xconnects.
+// Children are deleted one by one, so they are recorded in audit log instead of being removed by cascade
+func (h *Handler) syncDeleteInterface(tx pgx.Tx, r *http.Request, q *godevmandb.Queries, id int64) error {
+	children := []struct {
+		audit  AuditScope
+		query  string
+		delete func(int64) error
+	}{
+		{AuditInterface, `SELECT if_id FROM interfaces WHERE parent = $1 ORDER BY if_id`,
+			func(c int64) error { return h.syncDeleteInterface(tx, r, q, c) }},
+		{AuditSubinterface, `SELECT sif_id FROM subinterfaces WHERE if_id = $1 ORDER BY sif_id`,
+			func(c int64) error { return q.DeleteSubinterface(h.ctx, c) }},
+		{AuditXconnect, `SELECT xc_id FROM xconnects WHERE if_id = $1 ORDER BY xc_id`,
+			func(c int64) error { return q.DeleteXconnect(h.ctx, c) }},
+	}
+
+	for _, c := range children {
+		rows, err := tx.Query(h.ctx, c.query, id)
+		if err != nil {
+			return err
+		}
+		ids := []int64{}
+		for rows.Next() {
+			var cid int64
+			if err := rows.Scan(&cid); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, cid)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, cid := range ids {
+			_, err := h.txAudited(tx, r, c.audit, "delete", cid, func() (int64, error) {
+				return cid, c.delete(cid)
+			})
+			if err != nil && !errors.Is(err, errRowNotFound) {
+				return err
+			}
+		}
+	}
+
+	return q.DeleteInterface(h.ctx, id)
+}
+
+// Replace reference field of snapshot row by ID of referred row
+func syncRef(it map[string]any, field, target string, ids map[string]int64, required bool) error {
+	v, ok := it[field]
+	if !ok || v == nil {
+		if required {
+			return fmt.Errorf("%w: missing %s", errSnapshot, field)
+		}
+		return nil
+	}
+	delete(it, field)
+
+	id, ok := ids[fmt.Sprint(v)]
+	if !ok {
+		return fmt.Errorf("%w: unknown %s %q", errSnapshot, field, fmt.Sprint(v))
+	}
+	it[target] = id
+
+	return nil
+}
+
+// Sync Device
+// @Summary Sync device
+// @Description Reconcile device child collections with snapshot in single transaction.
+// @Description Rows are matched by natural key (interfaces: descr, subinterfaces: if_descr and descr, ip_interfaces: ip_addr,
+// @Description vlans: vlan and descr, entities: snmp_ent_id, xconnects: vc_idx or vc_id and peer_ip if vc_idx is not set, ospf_nbrs: nbr_ip,
+// @Description rl_nbrs: nbr_sysname and nbr_ent_id).
+// @Description Matched rows are updated with fields present in snapshot row, new rows are created and rows missing from snapshot are deleted.
+// @Description Child interfaces, subinterfaces and xconnects of deleted interface are deleted and audited with it.
+// @Description Collection omitted from snapshot is not changed. Parent entities and interfaces must precede their children in snapshot
+// @Tags devices
+// @ID sync-device
+// @Param dev_id path string true "dev_id"
+// @Param archive query bool false "archive removed interfaces and subinterfaces"
+// @Param Body body deviceSnapshot true "JSON object of deviceSnapshot"
+// @Success 200 {object} deviceSyncResult
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Device not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/{dev_id}/sync [PUT]
+func (h *Handler) SyncDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	archive := false
+	if v := r.URL.Query().Get("archive"); v != "" {
+		archive, err = strconv.ParseBool(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid archive value")
+			return
+		}
+	}
+
+	var p deviceSnapshot
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	tx, err := h.db.Begin(h.ctx)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(h.ctx)
+
+	// Lock device
+	err = tx.QueryRow(h.ctx, `SELECT dev_id FROM devices WHERE dev_id = $1 FOR UPDATE`, id).Scan(&id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Device not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	res := deviceSyncResult{DevID: id, Changes: make(map[string]*syncSummary)}
+	if err := h.syncDevice(tx, r, id, p, archive, res.Changes); err != nil {
+		switch {
+		case errors.Is(err, errSnapshot):
+			RespondError(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, errSyncDenied):
+			RespondError(w, r, http.StatusForbidden, err.Error())
+		default:
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if err := tx.Commit(h.ctx); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Reconcile device child collections with snapshot in transaction
+func (h *Handler) syncDevice(tx pgx.Tx, r *http.Request, devID int64, p deviceSnapshot, archive bool, changes map[string]*syncSummary) error {
+	q := godevmandb.New(h.db).WithTx(tx)
+
+	// Snapshot rows belong to synced device
+	for _, items := range [][]map[string]any{p.Entities, p.Interfaces, p.IpInterfaces, p.Vlans, p.Xconnects, p.OspfNbrs, p.RlNbrs} {
+		for _, it := range items {
+			it["dev_id"] = devID
+		}
+	}
+
+	dev, err := q.GetDevice(h.ctx, devID)
+	if err != nil {
+		return err
+	}
+
+	// Entities. Removed after interfaces
+	entities := syncSpec[godevmandb.Entity]{
+		audit: AuditEntity,
+		scope: ScopeEntity,
+		id:    func(s godevmandb.Entity) int64 { return s.EntID },
+		key:   func(s godevmandb.Entity) string { return syncKey(s.SnmpEntID) },
+		state: func(s godevmandb.Entity) any { return entityParams(s) },
+		create: func(s godevmandb.Entity) (int64, error) {
+			res, err := q.CreateEntity(h.ctx, entityParams(s))
+			return res.EntID, err
+		},
+		update: func(id int64, s godevmandb.Entity) error {
+			c := entityParams(s)
+			_, err := q.UpdateEntity(h.ctx, godevmandb.UpdateEntityParams{
+				EntID:        id,
+				ParentEntID:  c.ParentEntID,
+				SnmpEntID:    c.SnmpEntID,
+				DevID:        c.DevID,
+				Slot:         c.Slot,
+				Descr:        c.Descr,
+				Model:        c.Model,
+				HwProduct:    c.HwProduct,
+				HwRevision:   c.HwRevision,
+				SerialNr:     c.SerialNr,
+				SwProduct:    c.SwProduct,
+				SwRevision:   c.SwRevision,
+				Manufacturer: c.Manufacturer,
+				Physical:     c.Physical,
+			})
+			return err
+		},
+		delete: func(id int64) error { return q.DeleteEntity(h.ctx, id) },
+		ids:    make(map[string]int64),
+	}
+	entities.prepare = func(it map[string]any) error {
+		if v, ok := it["snmp_ent_id"]; !ok || v == nil {
+			return fmt.Errorf("%w: missing snmp_ent_id of entity", errSnapshot)
+		}
+		return syncRef(it, "parent_snmp_ent_id", "parent_ent_id", entities.ids, false)
+	}
+
+	var goneEntities []godevmandb.Entity
+	if p.Entities != nil {
+		cur, err := q.GetDeviceEntities(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		changes["entities"] = &syncSummary{}
+		goneEntities, err = syncRows(h, tx, r, entities, cur, p.Entities, changes["entities"])
+		if err != nil {
+			return err
+		}
+	} else if p.Interfaces != nil {
+		// Entity references of interfaces are resolved by current entities
+		cur, err := q.GetDeviceEntities(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range cur {
+			if s.SnmpEntID != nil {
+				entities.ids[syncKey(s.SnmpEntID)] = s.EntID
+			}
+		}
+	}
+
+	// Interfaces. Removed after rows referring to them
+	interfaces := syncSpec[iface]{
+		audit: AuditInterface,
+		scope: ScopeInterface,
+		id:    func(s iface) int64 { return s.IfID },
+		key:   func(s iface) string { return s.Descr },
+		state: func(s iface) any { return s.createParams() },
+		create: func(s iface) (int64, error) {
+			res, err := q.CreateInterface(h.ctx, s.createParams())
+			return res.IfID, err
+		},
+		update: func(id int64, s iface) error {
+			p := s.updateParams()
+			p.IfID = id
+			_, err := q.UpdateInterface(h.ctx, p)
+			return err
+		},
+		delete: func(id int64) error { return h.syncDeleteInterface(tx, r, q, id) },
+		archive: func(s iface) (int64, error) {
+			dt, err := q.GetDeviceDeviceType(h.ctx, devID)
+			if err != nil {
+				return 0, err
+			}
+
+			res, err := q.CreateArchivedInterface(h.ctx, godevmandb.CreateArchivedInterfaceParams{
+				Ifindex:      s.Ifindex,
+				OtnIfID:      s.OtnIfID,
+				Hostname:     dev.HostName,
+				HostIp4:      dev.Ip4Addr,
+				HostIp6:      dev.Ip6Addr,
+				Manufacturer: dt.Manufacturer,
+				Model:        dt.Model,
+				Descr:        s.Descr,
+				Alias:        s.Alias,
+				TypeEnum:     s.TypeEnum,
+				Mac:          strToPgMacaddr(s.Mac),
+			})
+			return res.IfaID, err
+		},
+		arcAud: AuditArchivedInterface,
+		ids:    make(map[string]int64),
+	}
+	interfaces.prepare = func(it map[string]any) error {
+		if err := syncRef(it, "parent_descr", "parent", interfaces.ids, false); err != nil {
+			return err
+		}
+		return syncRef(it, "ent_snmp_ent_id", "ent_id", entities.ids, false)
+	}
+
+	var goneInterfaces []iface
+	ifDescr := make(map[int64]string)
+	if p.Interfaces != nil {
+		res, err := q.GetDeviceInterfaces(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		cur := make([]iface, 0, len(res))
+		for _, s := range res {
+			a := iface{}
+			a.getValues(s)
+			cur = append(cur, a)
+			ifDescr[a.IfID] = a.Descr
+		}
+
+		changes["interfaces"] = &syncSummary{}
+		goneInterfaces, err = syncRows(h, tx, r, interfaces, cur, p.Interfaces, changes["interfaces"])
+		if err != nil {
+			return err
+		}
+	} else if p.Subinterfaces != nil || p.Xconnects != nil {
+		// Interface references are resolved by current interfaces
+		res, err := q.GetDeviceInterfaces(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range res {
+			interfaces.ids[s.Descr] = s.IfID
+			ifDescr[s.IfID] = s.Descr
+		}
+	}
+
+	// Subinterfaces
+	if p.Subinterfaces != nil {
+		s := syncSpec[subinterface]{
+			audit: AuditSubinterface,
+			scope: ScopeSubinterface,
+			id:    func(s subinterface) int64 { return s.SifID },
+			key:   func(s subinterface) string { return syncKey(s.IfID, s.Descr) },
+			state: func(s subinterface) any { return s.createParams() },
+			prepare: func(it map[string]any) error {
+				return syncRef(it, "if_descr", "if_id", interfaces.ids, true)
+			},
+			create: func(s subinterface) (int64, error) {
+				res, err := q.CreateSubinterface(h.ctx, s.createParams())
+				return res.SifID, err
+			},
+			update: func(id int64, s subinterface) error {
+				p := s.updateParams()
+				p.SifID = id
+				_, err := q.UpdateSubinterface(h.ctx, p)
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteSubinterface(h.ctx, id) },
+			archive: func(s subinterface) (int64, error) {
+				var parent *string
+				if s.IfID != nil {
+					if v, ok := ifDescr[*s.IfID]; ok {
+						parent = &v
+					}
+				}
+
+				res, err := q.CreateArchivedSubinterface(h.ctx, godevmandb.CreateArchivedSubinterfaceParams{
+					Ifindex:     s.Ifindex,
+					Descr:       s.Descr,
+					ParentDescr: parent,
+					Alias:       s.Alias,
+					Type:        s.TypeEnum,
+					Mac:         strToPgMacaddr(s.Mac),
+					Hostname:    dev.HostName,
+					HostIp4:     dev.Ip4Addr,
+					HostIp6:     dev.Ip6Addr,
+					Notes:       s.Notes,
+				})
+				return res.SifaID, err
+			},
+			arcAud: AuditArchivedSubinterface,
+			ids:    make(map[string]int64),
+		}
+
+		rows, err := tx.Query(h.ctx,
+			`SELECT `+strings.Join(modelColumns(reflect.TypeOf(godevmandb.Subinterface{})), ", ")+` FROM subinterfaces
+			  WHERE if_id IN (SELECT if_id FROM interfaces WHERE dev_id = $1)`, devID)
+		if err != nil {
+			return err
+		}
+
+		cur := []subinterface{}
+		for rows.Next() {
+			m, err := scanModel[godevmandb.Subinterface](rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			a := subinterface{}
+			a.getValues(m)
+			cur = append(cur, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		changes["subinterfaces"] = &syncSummary{}
+		gone, err := syncRows(h, tx, r, s, cur, p.Subinterfaces, changes["subinterfaces"])
+		if err != nil {
+			return err
+		}
+		if err := syncRemove(h, tx, r, s, gone, archive, changes["subinterfaces"]); err != nil {
+			return err
+		}
+	}
+
+	// IP interfaces
+	if p.IpInterfaces != nil {
+		s := syncSpec[ipInterface]{
+			audit: AuditIpInterface,
+			scope: ScopeIpInterface,
+			id:    func(s ipInterface) int64 { return s.IpID },
+			key:   func(s ipInterface) string { return inetKey(s.IpAddr) },
+			state: func(s ipInterface) any { return s.createParams() },
+			create: func(s ipInterface) (int64, error) {
+				res, err := q.CreateIpInterface(h.ctx, s.createParams())
+				return res.IpID, err
+			},
+			update: func(id int64, s ipInterface) error {
+				p := s.updateParams()
+				p.IpID = id
+				_, err := q.UpdateIpInterface(h.ctx, p)
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteIpInterface(h.ctx, id) },
+			ids:    make(map[string]int64),
+		}
+
+		res, err := q.GetDeviceIpInterfaces(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		cur := make([]ipInterface, 0, len(res))
+		for _, m := range res {
+			a := ipInterface{}
+			a.getValues(m)
+			cur = append(cur, a)
+		}
+
+		changes["ip_interfaces"] = &syncSummary{}
+		if err := syncCollection(h, tx, r, s, cur, p.IpInterfaces, changes["ip_interfaces"]); err != nil {
+			return err
+		}
+	}
+
+	// Vlans
+	if p.Vlans != nil {
+		s := syncSpec[godevmandb.Vlan]{
+			audit: AuditVlan,
+			scope: ScopeVlan,
+			id:    func(s godevmandb.Vlan) int64 { return s.VID },
+			key:   func(s godevmandb.Vlan) string { return syncKey(s.Vlan, s.Descr) },
+			state: func(s godevmandb.Vlan) any {
+				return godevmandb.CreateVlanParams{DevID: s.DevID, Vlan: s.Vlan, Descr: s.Descr}
+			},
+			create: func(s godevmandb.Vlan) (int64, error) {
+				res, err := q.CreateVlan(h.ctx, godevmandb.CreateVlanParams{DevID: s.DevID, Vlan: s.Vlan, Descr: s.Descr})
+				return res.VID, err
+			},
+			update: func(id int64, s godevmandb.Vlan) error {
+				_, err := q.UpdateVlan(h.ctx, godevmandb.UpdateVlanParams{VID: id, DevID: s.DevID, Vlan: s.Vlan, Descr: s.Descr})
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteVlan(h.ctx, id) },
+			ids:    make(map[string]int64),
+		}
+
+		cur, err := q.GetDeviceVlans(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		changes["vlans"] = &syncSummary{}
+		if err := syncCollection(h, tx, r, s, cur, p.Vlans, changes["vlans"]); err != nil {
+			return err
+		}
+	}
+
+	// Xconnects
+	if p.Xconnects != nil {
+		s := syncSpec[xconnect]{
+			audit: AuditXconnect,
+			scope: ScopeXconnect,
+			id:    func(s xconnect) int64 { return s.XcID },
+			key:   xconnectKey,
+			state: func(s xconnect) any { return s.createParams() },
+			prepare: func(it map[string]any) error {
+				return syncRef(it, "if_descr", "if_id", interfaces.ids, false)
+			},
+			create: func(s xconnect) (int64, error) {
+				res, err := q.CreateXconnect(h.ctx, s.createParams())
+				return res.XcID, err
+			},
+			update: func(id int64, s xconnect) error {
+				p := s.updateParams()
+				p.XcID = id
+				_, err := q.UpdateXconnect(h.ctx, p)
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteXconnect(h.ctx, id) },
+			ids:    make(map[string]int64),
+		}
+
+		res, err := q.GetDeviceXconnects(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		cur := make([]xconnect, 0, len(res))
+		for _, m := range res {
+			a := xconnect{}
+			a.getValues(m)
+			cur = append(cur, a)
+		}
+
+		changes["xconnects"] = &syncSummary{}
+		if err := syncCollection(h, tx, r, s, cur, p.Xconnects, changes["xconnects"]); err != nil {
+			return err
+		}
+	}
+
+	// OSPF neighbours
+	if p.OspfNbrs != nil {
+		s := syncSpec[ospfNbr]{
+			audit: AuditOspfNbr,
+			scope: ScopeOspfNbr,
+			id:    func(s ospfNbr) int64 { return s.NbrID },
+			key:   func(s ospfNbr) string { return inetKey(s.NbrIp) },
+			state: func(s ospfNbr) any { return s.createParams() },
+			create: func(s ospfNbr) (int64, error) {
+				res, err := q.CreateOspfNbr(h.ctx, s.createParams())
+				return res.NbrID, err
+			},
+			update: func(id int64, s ospfNbr) error {
+				p := s.updateParams()
+				p.NbrID = id
+				_, err := q.UpdateOspfNbr(h.ctx, p)
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteOspfNbr(h.ctx, id) },
+			ids:    make(map[string]int64),
+		}
+
+		res, err := q.GetDeviceOspfNbrs(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		cur := make([]ospfNbr, 0, len(res))
+		for _, m := range res {
+			a := ospfNbr{}
+			a.getValues(m)
+			cur = append(cur, a)
+		}
+
+		changes["ospf_nbrs"] = &syncSummary{}
+		if err := syncCollection(h, tx, r, s, cur, p.OspfNbrs, changes["ospf_nbrs"]); err != nil {
+			return err
+		}
+	}
+
+	// RL neighbours
+	if p.RlNbrs != nil {
+		s := syncSpec[godevmandb.RlNbr]{
+			audit: AuditRlNbr,
+			scope: ScopeRlNbr,
+			id:    func(s godevmandb.RlNbr) int64 { return s.NbrID },
+			key:   func(s godevmandb.RlNbr) string { return syncKey(s.NbrSysname, s.NbrEntID) },
+			state: func(s godevmandb.RlNbr) any {
+				return godevmandb.CreateRlNbrParams{DevID: s.DevID, NbrEntID: s.NbrEntID, NbrSysname: s.NbrSysname}
+			},
+			create: func(s godevmandb.RlNbr) (int64, error) {
+				res, err := q.CreateRlNbr(h.ctx, godevmandb.CreateRlNbrParams{DevID: s.DevID, NbrEntID: s.NbrEntID, NbrSysname: s.NbrSysname})
+				return res.NbrID, err
+			},
+			update: func(id int64, s godevmandb.RlNbr) error {
+				_, err := q.UpdateRlNbr(h.ctx, godevmandb.UpdateRlNbrParams{NbrID: id, DevID: s.DevID, NbrEntID: s.NbrEntID, NbrSysname: s.NbrSysname})
+				return err
+			},
+			delete: func(id int64) error { return q.DeleteRlNbr(h.ctx, id) },
+			ids:    make(map[string]int64),
+		}
+
+		cur, err := q.GetDeviceRlNbrs(h.ctx, devID)
+		if err != nil {
+			return err
+		}
+
+		changes["rl_nbrs"] = &syncSummary{}
+		if err := syncCollection(h, tx, r, s, cur, p.RlNbrs, changes["rl_nbrs"]); err != nil {
+			return err
+		}
+	}
+
+	// Vanished interfaces and entities
+	if p.Interfaces != nil {
+		if err := syncRemove(h, tx, r, interfaces, goneInterfaces, archive, changes["interfaces"]); err != nil {
+			return err
+		}
+	}
+	if p.Entities != nil {
+		if err := syncRemove(h, tx, r, entities, goneEntities, archive, changes["entities"]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reconcile collection and remove rows missing from snapshot
+func syncCollection[T any](h *Handler, tx pgx.Tx, r *http.Request, s syncSpec[T], cur []T, items []map[string]any, sum *syncSummary) error {
+	gone, err := syncRows(h, tx, r, s, cur, items, sum)
+	if err != nil {
+		return err
+	}
+
+	return syncRemove(h, tx, r, s, gone, false, sum)
+}
+
+// Create parameters of entity
+func entityParams(s godevmandb.Entity) godevmandb.CreateEntityParams {
+	return godevmandb.CreateEntityParams{
+		ParentEntID:  s.ParentEntID,
+		SnmpEntID:    s.SnmpEntID,
+		DevID:        s.DevID,
+		Slot:         s.Slot,
+		Descr:        s.Descr,
+		Model:        s.Model,
+		HwProduct:    s.HwProduct,
+		HwRevision:   s.HwRevision,
+		SerialNr:     s.SerialNr,
+		SwProduct:    s.SwProduct,
+		SwRevision:   s.SwRevision,
+		Manufacturer: s.Manufacturer,
+		Physical:     s.Physical,
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import "testing"
+
+func TestXconnectKey(t *testing.T) {
+	ip1, ip2 := "10.0.0.1", "10.0.0.2"
+
+	tests := []struct {
+		name string
+		a, b xconnect
+		same bool
+	}{
+		{"same vc_idx", xconnect{VcIdx: 5, VcID: 100}, xconnect{VcIdx: 5, VcID: 200}, true},
+		{"different vc_idx", xconnect{VcIdx: 5, VcID: 100}, xconnect{VcIdx: 6, VcID: 100}, false},
+		{"no vc_idx same vc", xconnect{VcID: 100, PeerIp: &ip1}, xconnect{VcID: 100, PeerIp: &ip1}, true},
+		{"no vc_idx different vc_id", xconnect{VcID: 100, PeerIp: &ip1}, xconnect{VcID: 200, PeerIp: &ip1}, false},
+		{"no vc_idx different peer", xconnect{VcID: 100, PeerIp: &ip1}, xconnect{VcID: 100, PeerIp: &ip2}, false},
+		{"no vc_idx and vc_idx", xconnect{VcID: 100}, xconnect{VcIdx: 100}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ka, kb := xconnectKey(tt.a), xconnectKey(tt.b)
+			if (ka == kb) != tt.same {
+				t.Errorf("keys %q and %q, want same %v", ka, kb, tt.same)
+			}
+		})
+	}
+}
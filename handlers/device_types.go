@@ -11,17 +11,33 @@ import (
 
 // Count DeviceTypes
 // @Summary Count device_types
-// @Description Count number of device types
+// @Description Count number of device types matching filters
 // @Tags devices
 // @ID count-device_types
+// @Param sys_id_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param manufacturer_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param model_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param hc_f query bool false "values 'true', 'false'"
+// @Param snmp_ver_f query string false "0|1|2|3"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/types/count [GET]
 func (h *Handler) CountDeviceTypes(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountDeviceTypes(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &deviceTypesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +46,24 @@ func (h *Handler) CountDeviceTypes(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of device types
+var deviceTypesList = listSpec{
+	table: "device_types",
+	keys:  []string{"sys_id"},
+	model: godevmandb.DeviceType{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"sys_id_f", "sys_id", filterILike},
+		{"manufacturer_f", "manufacturer", filterILike},
+		{"model_f", "model", filterILike},
+		{"hc_f", "hc", filterBool},
+		{"snmp_ver_f", "snmp_ver", filterEq},
+	},
+}
+
 // List device_types
 // @Summary List device_types
 // @Description List device types info
@@ -41,70 +75,39 @@ func (h *Handler) CountDeviceTypes(w http.ResponseWriter, r *http.Request) {
 // @Param hc_f query bool false "values 'true', 'false'"
 // @Param snmp_ver_f query string false "0|1|2|3"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.DeviceType
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/types [GET]
 func (h *Handler) GetDeviceTypes(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetDeviceTypesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("sys_id_f"); v != "" {
-		p.SysIDF = v
-	}
-
-	if v := r.FormValue("manufacturer_f"); v != "" {
-		p.ManufacturerF = v
-	}
-
-	if v := r.FormValue("model_f"); v != "" {
-		p.ModelF = v
-	}
-
-	if v := r.FormValue("hc_f"); v != "" {
-		p.HcF = v
-	}
-
-	if v := r.FormValue("snmp_ver_f"); v != "" {
-		p.SnmpVerF = v
+	// Query DB
+	l, err := parseListRequest(r, &deviceTypesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetDeviceTypes(h.ctx, p)
+	res, page, err := listRows[godevmandb.DeviceType](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get DeviceType
@@ -113,7 +116,10 @@ func (h *Handler) GetDeviceTypes(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-device_type
 // @Param sys_id path string true "sys_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.DeviceType
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid sys_id"
 // @Failure 404 {object} StatusResponse "Domain not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -182,10 +188,13 @@ func (h *Handler) CreateDeviceType(w http.ResponseWriter, r *http.Request) {
 // @ID update-device_type
 // @Param sys_id path string true "sys_id"
 // @Param Body body godevmandb.UpdateDeviceTypeParams true "JSON object of godevmandb.UpdateDeviceTypeParams.<br />Ignored fields:<ul><li>sys_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.DeviceType
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/types/{sys_id} [PUT]
 func (h *Handler) UpdateDeviceType(w http.ResponseWriter, r *http.Request) {
@@ -212,16 +221,39 @@ func (h *Handler) UpdateDeviceType(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch DeviceType
+// @Summary Patch device_type
+// @Description Partially update device_type with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device_type
+// @Param sys_id path string true "sys_id"
+// @Param Body body godevmandb.UpdateDeviceTypeParams true "JSON merge patch of godevmandb.UpdateDeviceTypeParams.<br />Ignored fields:<ul><li>sys_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.DeviceType
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/types/{sys_id} [PATCH]
+func (h *Handler) PatchDeviceType(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDeviceType, h.UpdateDeviceType)
+}
+
 // Delete DeviceType
 // @Summary Delete device_type
 // @Description Delete device type
 // @Tags devices
 // @ID delete-device_type
 // @Param sys_id path string true "sys_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid sys_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/types/{sys_id} [DELETE]
 func (h *Handler) DeleteDeviceType(w http.ResponseWriter, r *http.Request) {
@@ -286,6 +318,13 @@ func (h *Handler) GetDeviceTypeDevices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfDevice, res, func(s godevmandb.Device) int64 { return s.DevID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []device{}
 	for _, s := range res {
 		a := device{}
@@ -138,17 +138,45 @@ func (r *device) updateParams() godevmandb.UpdateDeviceParams {
 
 // Count Devices
 // @Summary Count devices
-// @Description Count number of devices
+// @Description Count number of devices matching filters
 // @Tags devices
 // @ID count-devices
+// @Param sys_id_f query string false "url encoded SQL 'LIKE' operator pattern"
+// @Param host_name_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param source_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param sys_name_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param sw_version_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param ext_model_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param ip4_addr_f query string false "ip or containing net in CIDR notation"
+// @Param ip6_addr_f query string false "ip or containing net in CIDR notation"
+// @Param installed_f query bool false "values 'true', 'false'"
+// @Param monitor_f query bool false "values 'true', 'false'"
+// @Param graph_f query bool false "values 'true', 'false'"
+// @Param backup_f query bool false "values 'true', 'false'"
+// @Param type_changed_f query bool false "values 'true', 'false'"
+// @Param backup_failed_f query bool false "values 'true', 'false'"
+// @Param validation_failed_f query bool false "values 'true', 'false'"
+// @Param unresponsive_f query bool false "values 'true', 'false'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/count [GET]
 func (h *Handler) CountDevices(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountDevices(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &devicesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -157,6 +185,37 @@ func (h *Handler) CountDevices(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of devices
+var devicesList = listSpec{
+	table:  "devices",
+	keys:   []string{"dev_id"},
+	domain: domainOfDevice,
+	model:  device{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"sys_id_f", "sys_id", filterILike},
+		{"host_name_f", "host_name", filterILike},
+		{"source_f", "source", filterILike},
+		{"sw_version_f", "sw_version", filterILikeNull},
+		{"notes_f", "notes", filterILikeNull},
+		{"sys_name_f", "sys_name", filterILikeNull},
+		{"ext_model_f", "ext_model", filterILikeNull},
+		{"ip4_addr_f", "ip4_addr", filterInet},
+		{"ip6_addr_f", "ip6_addr", filterInet},
+		{"installed_f", "installed", filterBool},
+		{"monitor_f", "monitor", filterBool},
+		{"graph_f", "graph", filterBool},
+		{"backup_f", "backup", filterBool},
+		{"type_changed_f", "type_changed", filterBool},
+		{"backup_failed_f", "backup_failed", filterBool},
+		{"validation_failed_f", "validation_failed", filterBool},
+		{"unresponsive_f", "unresponsive", filterBool},
+	},
+}
+
 // List devices
 // @Summary List devices
 // @Description List devices info
@@ -180,114 +239,33 @@ func (h *Handler) CountDevices(w http.ResponseWriter, r *http.Request) {
 // @Param validation_failed_f query bool false "values 'true', 'false'"
 // @Param unresponsive_f query bool false "values 'true', 'false'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} device
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices [GET]
 func (h *Handler) GetDevices(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetDevicesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("sys_id_f"); v != "" {
-		p.SysIDF = v
-	}
-
-	if v := r.FormValue("host_name_f"); v != "" {
-		p.HostNameF = v
-	}
-
-	if v := r.FormValue("source_f"); v != "" {
-		p.SourceF = v
-	}
-
-	if v := r.FormValue("sw_version_f"); v != "" {
-		p.SwVersionF = &v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
-	}
-
-	if v := r.FormValue("sys_name_f"); v != "" {
-		p.SysNameF = &v
-	}
-
-	if v := r.FormValue("ext_model_f"); v != "" {
-		p.ExtModelF = &v
-	}
-
-	p.Ip4AddrF = strToPgInet(nil)
-	if v := r.FormValue("ip4_addr_f"); v != "" {
-		p.Ip4AddrF = strToPgInet(&v)
-	}
-
-	p.Ip6AddrF = strToPgInet(nil)
-	if v := r.FormValue("ip4_aip6_addr_fddr_f"); v != "" {
-		p.Ip6AddrF = strToPgInet(&v)
-	}
-
-	if v := r.FormValue("installed_f"); v != "" {
-		p.InstalledF = v
-	}
-
-	if v := r.FormValue("monitor_f"); v != "" {
-		p.MonitorF = v
-	}
-
-	if v := r.FormValue("graph_f"); v != "" {
-		p.GraphF = v
-	}
-
-	if v := r.FormValue("backup_f"); v != "" {
-		p.BackupF = v
-	}
-
-	if v := r.FormValue("type_changed_f"); v != "" {
-		p.TypeChangedF = v
-	}
-
-	if v := r.FormValue("backup_failed_f"); v != "" {
-		p.BackupFailedF = v
-	}
-
-	if v := r.FormValue("validation_failed_f"); v != "" {
-		p.ValidationFailedF = v
-	}
-
-	if v := r.FormValue("unresponsive_f"); v != "" {
-		p.UnresponsiveF = v
+	// Query DB
+	l, err := parseListRequest(r, &devicesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetDevices(h.ctx, p)
+	res, page, err := listRows[godevmandb.Device](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -300,7 +278,7 @@ func (h *Handler) GetDevices(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get Device
@@ -309,7 +287,10 @@ func (h *Handler) GetDevices(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-device
 // @Param dev_id path string true "dev_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} device
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid dev_id"
 // @Failure 404 {object} StatusResponse "Device not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -384,10 +365,13 @@ func (h *Handler) CreateDevice(w http.ResponseWriter, r *http.Request) {
 // @ID update-device
 // @Param dev_id path string true "dev_id"
 // @Param Body body device true "JSON object of device.<br />Ignored fields:<ul><li>dev_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} device
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/{dev_id} [PUT]
 func (h *Handler) UpdateDevice(w http.ResponseWriter, r *http.Request) {
@@ -423,16 +407,39 @@ func (h *Handler) UpdateDevice(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch Device
+// @Summary Patch device
+// @Description Partially update device with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-device
+// @Param dev_id path string true "dev_id"
+// @Param Body body device true "JSON merge patch of device.<br />Ignored fields:<ul><li>dev_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} device
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/{dev_id} [PATCH]
+func (h *Handler) PatchDevice(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetDevice, h.UpdateDevice)
+}
+
 // Delete Device
 // @Summary Delete device
 // @Description Delete device
 // @Tags devices
 // @ID delete-device
 // @Param dev_id path string true "dev_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid dev_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/{dev_id} [DELETE]
 func (h *Handler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
@@ -452,6 +459,50 @@ func (h *Handler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of devices
+var devicesBulk = bulkSpec{
+	scope: ScopeDevice,
+	audit: AuditDevice,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var pIn device
+		if err := bulkDecode(b, &pIn); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateDevice(h.ctx, pIn.createParams())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		out := device{}
+		out.getValues(res)
+
+		return res.DevID, out, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var pIn device
+		if err := bulkDecode(b, &pIn); err != nil {
+			return nil, err
+		}
+
+		p := pIn.updateParams()
+		p.DevID = id
+
+		res, err := q.UpdateDevice(h.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		out := device{}
+		out.getValues(res)
+
+		return out, nil
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteDevice(h.ctx, id)
+	},
+}
+
 // Foreign key
 // Get Device DeviceDomain
 // @Summary Get device device_domain
@@ -668,6 +719,13 @@ func (h *Handler) GetDeviceChilds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfDevice, res, func(s godevmandb.Device) int64 { return s.DevID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []device{}
 	for _, s := range res {
 		a := device{}
@@ -681,7 +739,7 @@ func (h *Handler) GetDeviceChilds(w http.ResponseWriter, r *http.Request) {
 // Relations
 // List Device Credentials
 // @Summary List device credentials
-// @Description List device credentials info
+// @Description List device credentials info. Secrets are masked
 // @Tags devices
 // @ID list-device-credentials
 // @Param dev_id path string true "dev_id"
@@ -705,17 +763,9 @@ func (h *Handler) GetDeviceDeviceCredentials(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Decrypt secret
+	// Mask secret
 	for i, s := range res {
-		if s.EncSecret != "" {
-			val, err := godevmandb.DecryptStrAes(s.EncSecret, salt)
-			if err != nil {
-				RespondError(w, r, http.StatusInternalServerError, err.Error())
-				return
-			}
-
-			res[i].EncSecret = val
-		}
+		res[i].EncSecret = maskSecret(s.EncSecret)
 	}
 
 	RespondJSON(w, r, http.StatusOK, res)
@@ -979,6 +1029,13 @@ func (h *Handler) GetDevicePeerXconnects(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfXconnect, res, func(s godevmandb.Xconnect) int64 { return s.XcID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []xconnect{}
 	for _, s := range res {
 		a := xconnect{}
@@ -11,17 +11,39 @@ import (
 
 // Count Entities
 // @Summary Count entities
-// @Description Count number of entities
+// @Description Count number of entities matching filters
 // @Tags entities
 // @ID count-entities
+// @Param sys_name_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull'"
+// @Param slot_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param model_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param w_product_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param hw_revision_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param serial_nr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param sw_product_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param sw_revision_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param manufacturer_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param physical_f query bool false "values 'true', 'false'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/count [GET]
 func (h *Handler) CountEntities(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountEntities(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &entitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +52,31 @@ func (h *Handler) CountEntities(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of entities
+var entitiesList = listSpec{
+	table:  "entities",
+	keys:   []string{"ent_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.Entity{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"snmp_ent_id_f", "snmp_ent_id", filterEqNull},
+		{"slot_f", "slot", filterILikeNull},
+		{"descr_f", "descr", filterILikeNull},
+		{"model_f", "model", filterILikeNull},
+		{"hw_product_f", "hw_product", filterILikeNull},
+		{"hw_revision_f", "hw_revision", filterILikeNull},
+		{"serial_nr_f", "serial_nr", filterILikeNull},
+		{"sw_product_f", "sw_product", filterILikeNull},
+		{"sw_revision_f", "sw_revision", filterILikeNull},
+		{"manufacturer_f", "manufacturer", filterILikeNull},
+		{"physical_f", "physical", filterBool},
+	},
+}
+
 // List entities
 // @Summary List entities
 // @Description List entities info
@@ -47,94 +94,39 @@ func (h *Handler) CountEntities(w http.ResponseWriter, r *http.Request) {
 // @Param manufacturer_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
 // @Param physical_f query bool false "values 'true', 'false'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Entity
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities [GET]
 func (h *Handler) GetEntities(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetEntitiesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("snmp_ent_id_f"); v != "" {
-		p.SnmpEntIDF = &v
-	}
-
-	if v := r.FormValue("slot_f"); v != "" {
-		p.SlotF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
-	}
-
-	if v := r.FormValue("model_f"); v != "" {
-		p.ModelF = &v
-	}
-
-	if v := r.FormValue("hw_product_f"); v != "" {
-		p.HwProductF = &v
-	}
-
-	if v := r.FormValue("hw_revision_f"); v != "" {
-		p.HwRevisionF = &v
-	}
-
-	if v := r.FormValue("serial_nr_f"); v != "" {
-		p.SerialNrF = &v
-	}
-
-	if v := r.FormValue("sw_product_f"); v != "" {
-		p.SwProductF = &v
-	}
-
-	if v := r.FormValue("sw_revision_f"); v != "" {
-		p.SwRevisionF = &v
-	}
-
-	if v := r.FormValue("manufacturer_f"); v != "" {
-		p.ManufacturerF = &v
-	}
-
-	if v := r.FormValue("physical_f"); v != "" {
-		p.PhysicalF = v
+	// Query DB
+	l, err := parseListRequest(r, &entitiesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetEntities(h.ctx, p)
+	res, page, err := listRows[godevmandb.Entity](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get Entity
@@ -143,7 +135,10 @@ func (h *Handler) GetEntities(w http.ResponseWriter, r *http.Request) {
 // @Tags entities
 // @ID get-Entity
 // @Param ent_id path string true "ent_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Entity
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid ent_id"
 // @Failure 404 {object} StatusResponse "Entity not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -208,10 +203,13 @@ func (h *Handler) CreateEntity(w http.ResponseWriter, r *http.Request) {
 // @ID update-Entity
 // @Param ent_id path string true "ent_id"
 // @Param Body body godevmandb.UpdateEntityParams true "JSON object of godevmandb.UpdateEntityParams.<br />Ignored fields:<ul><li>ent_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Entity
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/{ent_id} [PUT]
 func (h *Handler) UpdateEntity(w http.ResponseWriter, r *http.Request) {
@@ -241,16 +239,39 @@ func (h *Handler) UpdateEntity(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Entity
+// @Summary Patch Entity
+// @Description Partially update Entity with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags entities
+// @ID patch-Entity
+// @Param ent_id path string true "ent_id"
+// @Param Body body godevmandb.UpdateEntityParams true "JSON merge patch of godevmandb.UpdateEntityParams.<br />Ignored fields:<ul><li>ent_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Entity
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/{ent_id} [PATCH]
+func (h *Handler) PatchEntity(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetEntity, h.UpdateEntity)
+}
+
 // Delete Entity
 // @Summary Delete Entity
 // @Description Delete Entity
 // @Tags entities
 // @ID delete-Entity
 // @Param ent_id path string true "ent_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid ent_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /entities/{ent_id} [DELETE]
 func (h *Handler) DeleteEntity(w http.ResponseWriter, r *http.Request) {
@@ -270,6 +291,55 @@ func (h *Handler) DeleteEntity(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of entities
+var entitiesBulk = bulkSpec{
+	scope: ScopeEntity,
+	audit: AuditEntity,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateEntityParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateEntity(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.EntID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateEntityParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.EntID = id
+
+		return q.UpdateEntity(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteEntity(h.ctx, id)
+	},
+}
+
+// Bulk Entities
+// @Summary Bulk change entities
+// @Description Create, update and delete entities in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags entities
+// @ID bulk-entities
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create items is JSON object of godevmandb.CreateEntityParams and data of update items is JSON object of godevmandb.UpdateEntityParams"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /entities/bulk [POST]
+func (h *Handler) BulkEntities(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, entitiesBulk)
+}
+
 // Foreign key
 // Get Entity Device
 // @Summary Get entity device
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// Entity and interfaces of device physical index
+type phyIndexLookup struct {
+	PhyIndex   godevmandb.EntityPhyIndex `json:"phy_index"`
+	Entity     godevmandb.Entity         `json:"entity"`
+	Interfaces []iface                   `json:"interfaces"`
+}
+
+// Count EntityPhyIndexes
+// @Summary Count entity_phy_indexes
+// @Description Count number of entity_phy_indexes matching filters
+// @Tags entities
+// @ID count-entity_phy_indexes
+// @Param phy_index_f query string false "SQL '=' operator value"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/count [GET]
+func (h *Handler) CountEntityPhyIndexes(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &entityPhyIndexesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List query specification of entity phy indexes
+var entityPhyIndexesList = listSpec{
+	table:  "entity_phy_indexes",
+	keys:   []string{"ei_id"},
+	domain: domainOfEntity,
+	model:  godevmandb.EntityPhyIndex{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"phy_index_f", "phy_index", filterEq},
+		{"descr_f", "descr", filterILike},
+	},
+}
+
+// List entity_phy_indexes
+// @Summary List entity_phy_indexes
+// @Description List entity_phy_indexes info
+// @Tags entities
+// @ID list-entity_phy_indexes
+// @Param phy_index_f query string false "SQL '=' operator value"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} godevmandb.EntityPhyIndex
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes [GET]
+func (h *Handler) GetEntityPhyIndexes(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &entityPhyIndexesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[godevmandb.EntityPhyIndex](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get EntityPhyIndex
+// @Summary Get entity_phy_index
+// @Description Get entity_phy_index info
+// @Tags entities
+// @ID get-entity_phy_index
+// @Param ei_id path string true "ei_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} godevmandb.EntityPhyIndex
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid ei_id"
+// @Failure 404 {object} StatusResponse "EntityPhyIndex not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/{ei_id} [GET]
+func (h *Handler) GetEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ei_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid entity phy index ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetEntityPhyIndex(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Entity phy index not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Lookup EntityPhyIndex
+// @Summary Lookup entity by physical index
+// @Description Get entity_phy_index, owning entity and its interfaces by device ID and physical index (entPhysicalIndex)
+// @Tags entities
+// @ID lookup-entity_phy_index
+// @Param dev_id path string true "dev_id"
+// @Param phy_index path string true "phy_index"
+// @Success 200 {object} phyIndexLookup
+// @Failure 400 {object} StatusResponse "Invalid dev_id or phy_index"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Entity phy index not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/lookup/{dev_id}/{phy_index} [GET]
+func (h *Handler) LookupEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	devID, err := strconv.ParseInt(chi.URLParam(r, "dev_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	phyIndex, err := strconv.ParseInt(chi.URLParam(r, "phy_index"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid phy index")
+		return
+	}
+
+	var id int64
+	err = h.db.QueryRow(h.ctx,
+		`SELECT p.ei_id
+		   FROM entity_phy_indexes p
+		   JOIN entities e ON e.ent_id = p.ent_id
+		  WHERE e.dev_id = $1 AND p.phy_index = $2
+		  ORDER BY p.ei_id
+		  LIMIT 1`, devID, phyIndex).Scan(&id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Entity phy index not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	out := phyIndexLookup{Interfaces: []iface{}}
+
+	out.PhyIndex, err = q.GetEntityPhyIndex(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out.Entity, err = q.GetEntity(h.ctx, out.PhyIndex.EntID)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res, err := q.GetEntityInterfaces(h.ctx, &out.Entity.EntID)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, s := range res {
+		a := iface{}
+		a.getValues(s)
+		out.Interfaces = append(out.Interfaces, a)
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Create EntityPhyIndex
+// @Summary Create entity_phy_index
+// @Description Create entity_phy_index
+// @Tags entities
+// @ID create-entity_phy_index
+// @Param Body body godevmandb.CreateEntityPhyIndexParams true "JSON object of godevmandb.CreateEntityPhyIndexParams"
+// @Success 201 {object} godevmandb.EntityPhyIndex
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes [POST]
+func (h *Handler) CreateEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	var p godevmandb.CreateEntityPhyIndexParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	q := godevmandb.New(h.db)
+	res, err := q.CreateEntityPhyIndex(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, res)
+}
+
+// Update EntityPhyIndex
+// @Summary Update entity_phy_index
+// @Description Update entity_phy_index
+// @Tags entities
+// @ID update-entity_phy_index
+// @Param ei_id path string true "ei_id"
+// @Param Body body godevmandb.UpdateEntityPhyIndexParams true "JSON object of godevmandb.UpdateEntityPhyIndexParams.<br />Ignored fields:<ul><li>ei_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.EntityPhyIndex
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/{ei_id} [PUT]
+func (h *Handler) UpdateEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ei_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid entity phy index ID")
+		return
+	}
+
+	var p godevmandb.UpdateEntityPhyIndexParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	p.EiID = id
+
+	q := godevmandb.New(h.db)
+	res, err := q.UpdateEntityPhyIndex(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Patch EntityPhyIndex
+// @Summary Patch entity_phy_index
+// @Description Partially update entity_phy_index with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags entities
+// @ID patch-entity_phy_index
+// @Param ei_id path string true "ei_id"
+// @Param Body body godevmandb.UpdateEntityPhyIndexParams true "JSON merge patch of godevmandb.UpdateEntityPhyIndexParams.<br />Ignored fields:<ul><li>ei_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.EntityPhyIndex
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/{ei_id} [PATCH]
+func (h *Handler) PatchEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetEntityPhyIndex, h.UpdateEntityPhyIndex)
+}
+
+// Delete EntityPhyIndex
+// @Summary Delete entity_phy_index
+// @Description Delete entity_phy_index
+// @Tags entities
+// @ID delete-entity_phy_index
+// @Param ei_id path string true "ei_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid ei_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/{ei_id} [DELETE]
+func (h *Handler) DeleteEntityPhyIndex(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ei_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid entity phy index ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	err = q.DeleteEntityPhyIndex(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Foreign key
+// Get EntityPhyIndex Entity
+// @Summary Get entity_phy_index entity
+// @Description Get entity_phy_index entity info
+// @Tags entities
+// @ID get-entity_phy_index-entity
+// @Param ei_id path string true "ei_id"
+// @Success 200 {object} godevmandb.Entity
+// @Failure 400 {object} StatusResponse "Invalid ei_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /entities/phy_indexes/{ei_id}/entity [GET]
+func (h *Handler) GetEntityPhyIndexEntity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ei_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid entity phy index ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetEntityPhyIndexEntity(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Entity tag of response payload
+func etagOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Internal header of additional entity tag source. Set by handlers of resources with
+// values masked in payload, so changes of masked values change entity tag too.
+// Removed from responses
+const etagSourceHeader = "X-Etag-Source"
+
+// Add digest of values masked in response payload to entity tag source
+func setETagSource(w http.ResponseWriter, vals ...string) {
+	sum := sha256.New()
+	for _, v := range vals {
+		sum.Write([]byte(v))
+		sum.Write([]byte{0})
+	}
+	w.Header().Set(etagSourceHeader, hex.EncodeToString(sum.Sum(nil)))
+}
+
+// Entity tag of recorded response. Includes additional entity tag source
+func recordedETag(rec *httptest.ResponseRecorder) string {
+	b := rec.Body.Bytes()
+	if v := rec.Header().Get(etagSourceHeader); v != "" {
+		b = append(append([]byte{}, b...), v...)
+	}
+
+	return etagOf(b)
+}
+
+// Check if entity tag matches list of entity tags in If-Match or If-None-Match header.
+// Weak comparison ignores W/ prefix of listed tags
+func etagMatch(header, etag string, weak bool) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if weak {
+			v = strings.TrimPrefix(v, "W/")
+		}
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy recorded response to response writer
+func writeRecorded(w http.ResponseWriter, rec *httptest.ResponseRecorder) {
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.Header().Del(etagSourceHeader)
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(rec.Body.Bytes())
+}
+
+// Conditional GET middleware.
+// Sets ETag header of successful GET response and returns 304 Not Modified
+// if it matches If-None-Match request header
+func (h *Handler) ConditionalGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+
+		if rec.Code == http.StatusOK {
+			etag := recordedETag(rec)
+			rec.Header().Set("ETag", etag)
+
+			if v := r.Header.Get("If-None-Match"); v != "" && etagMatch(v, etag, true) {
+				for k, v := range rec.Header() {
+					w.Header()[k] = v
+				}
+				w.Header().Del(etagSourceHeader)
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		writeRecorded(w, rec)
+	})
+}
+
+// Precondition middleware for write requests.
+// If request has If-Match header, current representation of resource is fetched
+// using get handler and request is rejected with 412 Precondition Failed
+// if its entity tag does not match. Resource row is locked before by Audit middleware
+// in transaction of request, so it can't change until write is committed.
+// Sets ETag header of successful update response of resource itself
+func (h *Handler) IfMatch(get http.HandlerFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if v := r.Header.Get("If-Match"); v != "" {
+				gr := r.Clone(r.Context())
+				gr.Method = http.MethodGet
+				gr.Body = http.NoBody
+				gr.ContentLength = 0
+
+				rec := httptest.NewRecorder()
+				get(rec, gr)
+
+				switch {
+				case rec.Code == http.StatusNotFound:
+					RespondError(w, r, http.StatusPreconditionFailed, "Precondition failed")
+					return
+				case rec.Code != http.StatusOK:
+					writeRecorded(w, rec)
+					return
+				case !etagMatch(v, recordedETag(rec), false):
+					RespondError(w, r, http.StatusPreconditionFailed, "Resource has been modified")
+					return
+				}
+			}
+
+			if r.Method == http.MethodDelete {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := httptest.NewRecorder()
+			next.ServeHTTP(rec, r)
+			rp := chi.RouteContext(r.Context()).RoutePatterns
+			if rec.Code == http.StatusOK && len(rp) > 0 && rp[len(rp)-1] == "/" {
+				rec.Header().Set("ETag", recordedETag(rec))
+			}
+
+			writeRecorded(w, rec)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog"
+	"github.com/jackc/pgx/v4"
+)
+
+// Number of rows fetched from export cursor at once
+const exportFetchSize = 500
+
+// Media type of newline delimited JSON
+const ndjsonType = "application/x-ndjson"
+
+// Export specification of resource
+type exportSpec struct {
+	list  *listSpec
+	model reflect.Type
+	// Scan row into response object
+	scan func(pgx.Rows) (any, error)
+}
+
+// Export specification of resource with sqlc model T.
+// Response object is converted from model by conv. Model is returned as is if conv is nil
+func exportOf[T any](l *listSpec, conv func(T) any) exportSpec {
+	return exportSpec{
+		list:  l,
+		model: reflect.TypeOf(*new(T)),
+		scan: func(rows pgx.Rows) (any, error) {
+			s, err := scanModel[T](rows)
+			if err != nil || conv == nil {
+				return s, err
+			}
+
+			return conv(s), nil
+		},
+	}
+}
+
+// Exportable resources
+var exportResources = map[string]exportSpec{
+	"archived_interfaces": exportOf(&archivedInterfacesList, func(s godevmandb.ArchivedInterface) any {
+		a := archivedInterface{}
+		a.getValues(s)
+		return a
+	}),
+	"archived_subinterfaces": exportOf(&archivedSubinterfacesList, func(s godevmandb.ArchivedSubinterface) any {
+		a := archivedSubinterface{}
+		a.getValues(s)
+		return a
+	}),
+	"con_capacities": exportOf[godevmandb.ConCapacity](&conCapacitiesList, nil),
+	"con_classes":    exportOf[godevmandb.ConClass](&conClassesList, nil),
+	"con_providers":  exportOf[godevmandb.ConProvider](&conProvidersList, nil),
+	"con_types":      exportOf[godevmandb.ConType](&conTypesList, nil),
+	"connections":    exportOf[godevmandb.Connection](&connectionsList, nil),
+	"countries":      exportOf[godevmandb.Country](&countriesList, nil),
+	"credentials": exportOf(&credentialsList, func(s godevmandb.Credential) any {
+		s.EncSecret = maskSecret(s.EncSecret)
+		return s
+	}),
+	"custom_entities": exportOf[godevmandb.CustomEntity](&customEntitiesList, nil),
+	"device_classes":  exportOf[godevmandb.DeviceClass](&deviceClassesList, nil),
+	"device_credentials": exportOf(&deviceCredentialsList, func(s godevmandb.DeviceCredential) any {
+		s.EncSecret = maskSecret(s.EncSecret)
+		return s
+	}),
+	"device_domains":    exportOf[godevmandb.DeviceDomain](&deviceDomainsList, nil),
+	"device_extensions": exportOf[godevmandb.DeviceExtension](&deviceExtensionsList, nil),
+	"device_licenses":   exportOf[godevmandb.DeviceLicense](&deviceLicensesList, nil),
+	"device_states":     exportOf[godevmandb.DeviceState](&deviceStatesList, nil),
+	"device_types":      exportOf[godevmandb.DeviceType](&deviceTypesList, nil),
+	"devices": exportOf(&devicesList, func(s godevmandb.Device) any {
+		a := device{}
+		a.getValues(s)
+		return a
+	}),
+	"entities":            exportOf[godevmandb.Entity](&entitiesList, nil),
+	"entity_phy_indexes":  exportOf[godevmandb.EntityPhyIndex](&entityPhyIndexesList, nil),
+	"int_bw_stats":        exportOf[godevmandb.IntBwStat](&intBwStatsList, nil),
+	"interface_relations": exportOf[godevmandb.InterfaceRelation](&interfaceRelationsList, nil),
+	"interfaces": exportOf(&interfacesList, func(s godevmandb.Interface) any {
+		a := iface{}
+		a.getValues(s)
+		return a
+	}),
+	"ip_interfaces": exportOf(&ipInterfacesList, func(s godevmandb.IpInterface) any {
+		a := ipInterface{}
+		a.getValues(s)
+		return a
+	}),
+	"ospf_nbrs": exportOf(&ospfNbrsList, func(s godevmandb.OspfNbr) any {
+		a := ospfNbr{}
+		a.getValues(s)
+		return a
+	}),
+	"otn_interfaces": exportOf[otnMapping](&otnMappingsList, nil),
+	"rl_nbrs":        exportOf[godevmandb.RlNbr](&rlNbrsList, nil),
+	"sites":          exportOf[godevmandb.Site](&sitesList, nil),
+	"snmp_credentials": exportOf(&snmpCredentialsList, func(s godevmandb.SnmpCredential) any {
+		a := snmpCredential{}
+		a.getValues(s)
+		return a
+	}),
+	"subinterfaces": exportOf(&subinterfacesList, func(s godevmandb.Subinterface) any {
+		a := subinterface{}
+		a.getValues(s)
+		return a
+	}),
+	"user_authzs": exportOf[godevmandb.UserAuthz](&userAuthzsList, nil),
+	"user_graphs": exportOf[godevmandb.UserGraph](&userGraphsList, nil),
+	"users":       exportOf[godevmandb.User](&usersList, nil),
+	"vars":        exportOf[godevmandb.Var](&varsList, nil),
+	"vlans":       exportOf[godevmandb.Vlan](&vlansList, nil),
+	"xconnects": exportOf(&xconnectsList, func(s godevmandb.Xconnect) any {
+		a := xconnect{}
+		a.getValues(s)
+		return a
+	}),
+}
+
+// Export Resource
+// @Summary Export resource
+// @Description Stream all rows of resource as newline delimited JSON (one response object per line).
+// @Description Accepts the same filters and sort fields as list route of resource. Pagination parameters are ignored.
+// @Description Resources: archived_interfaces, archived_subinterfaces, con_capacities, con_classes, con_providers, con_types,
+// @Description connections, countries, credentials, custom_entities, device_classes, device_credentials, device_domains,
+// @Description device_extensions, device_licenses, device_states, device_types, devices, entities, entity_phy_indexes,
+// @Description int_bw_stats, interface_relations, interfaces, ip_interfaces, ospf_nbrs, otn_interfaces, rl_nbrs, sites,
+// @Description snmp_credentials, subinterfaces, user_authzs, user_graphs, users, vars, vlans, xconnects
+// @Tags export
+// @ID export
+// @Param resource path string true "resource"
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Success 200 {string} string "newline delimited JSON objects"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Unknown resource"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /export/{resource} [GET]
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	hlog := httplog.LogEntry(r.Context())
+
+	s, ok := exportResources[chi.URLParam(r, "resource")]
+	if !ok {
+		RespondError(w, r, http.StatusNotFound, "Unknown resource")
+		return
+	}
+
+	l, err := parseListRequest(r, s.list)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, err := l.orderBy(s.model, false)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Cursor needs transaction. Rows are read from single snapshot.
+	// Export is stopped when client disconnects or request times out.
+	// Transaction is rolled back using handler context, which is not canceled
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(h.ctx)
+
+	_, err = tx.Exec(ctx,
+		`DECLARE export_cur NO SCROLL CURSOR FOR SELECT t.`+strings.Join(modelColumns(s.model), ", t.")+
+			` FROM `+s.list.table+` t`+l.whereClause()+` ORDER BY `+order,
+		l.args...)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	fetch := `FETCH ` + strconv.Itoa(exportFetchSize) + ` FROM export_cur`
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	// Response status can be set only before first row
+	started := false
+	start := func() {
+		if !started {
+			w.Header().Set("Content-Type", ndjsonType)
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+	}
+	fail := func(err error) {
+		if started {
+			hlog.Error().Msg("Export - " + err.Error())
+			return
+		}
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+	}
+
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		n := 0
+		for rows.Next() {
+			a, err := s.scan(rows)
+			if err != nil {
+				rows.Close()
+				fail(err)
+				return
+			}
+
+			start()
+			if err := enc.Encode(a); err != nil {
+				// Client has gone
+				rows.Close()
+				fail(err)
+				return
+			}
+			n++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			fail(err)
+			return
+		}
+
+		if n < exportFetchSize {
+			break
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	// Empty export
+	start()
+}
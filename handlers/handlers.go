@@ -5,26 +5,101 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
 
+	"github.com/aretaja/godevmanapi/config"
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httplog"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-// Global encryption salt
-var salt string
+// Global minimal userlevels for write, admin and secrets reveal access
+var writeLevel, adminLevel, revealLevel int32
+
+// Database connection of handler. Implemented by connection pool and transaction
+type dbConn interface {
+	godevmandb.DBTX
+	Begin(context.Context) (pgx.Tx, error)
+	BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Transaction as database connection. Nested transactions are savepoints
+// and inherit options of enclosing transaction
+type txConn struct {
+	pgx.Tx
+}
+
+func (c txConn) BeginTx(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return c.Begin(ctx)
+}
 
 type Handler struct {
 	ctx context.Context
-	db  *pgxpool.Pool
+	db  dbConn
+}
+
+// Copy of handler using transaction as database connection
+func (h *Handler) WithTx(tx pgx.Tx) *Handler {
+	return &Handler{ctx: h.ctx, db: txConn{tx}}
+}
+
+// Return true if database connection of handler is transaction.
+// Handlers with side effects outside of database refuse to run in transaction
+// which can be rolled back after they respond
+func (h *Handler) inTx() bool {
+	_, ok := h.db.(txConn)
+	return ok
+}
+
+// Transaction middleware for write requests.
+// Request is routed by routes built for copy of handler using transaction as database connection,
+// so change and its audit log record are committed together. Transaction is committed
+// if response status is below 300, otherwise it is rolled back. Response is sent after commit
+func (h *Handler) WriteTx(routes func(*Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || h.inTx() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tx, err := h.db.Begin(h.ctx)
+			if err != nil {
+				RespondError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			defer tx.Rollback(h.ctx)
+
+			// Request is routed by its own route context
+			req := r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, nil))
+			rec := httptest.NewRecorder()
+			routes(h.WithTx(tx)).ServeHTTP(rec, req)
+
+			if rec.Code < http.StatusMultipleChoices {
+				if err := tx.Commit(h.ctx); err != nil {
+					RespondError(w, r, http.StatusInternalServerError, err.Error())
+					return
+				}
+			}
+
+			writeRecorded(w, rec)
+		})
+	}
 }
 
 // Create connection pool
-func (h *Handler) Initialize(dbURL, s string) error {
+func (h *Handler) Initialize(c *config.Configuration) error {
 	h.ctx = context.Background()
-	salt = s
+	setKeys(c.Salt, c.OldSalt)
+	setTokenKey(c.TokenKey)
+	writeLevel = int32(c.WriteLevel)
+	adminLevel = int32(c.AdminLevel)
+	revealLevel = int32(c.RevealLevel)
 
-	pool, err := pgxpool.Connect(h.ctx, dbURL)
+	pool, err := pgxpool.Connect(h.ctx, c.DbURL)
 	if err != nil {
 		return err
 	}
@@ -70,8 +145,20 @@ func (h *Handler) Hello(w http.ResponseWriter, r *http.Request) {
 // @Router /version [GET]
 func VersionSwagger() {}
 
-// Regular response
+// Regular response. Successful list response of GET request is returned as CSV if requested.
+// Explicitly requested CSV format of other responses is rejected with 406 Not Acceptable
 func RespondJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	if code == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead) && wantCSV(r) {
+		switch {
+		case isList(payload):
+			respondCSV(w, r, code, payload)
+			return
+		case r.FormValue("format") == "csv":
+			RespondError(w, r, http.StatusNotAcceptable, "CSV format is supported only for lists")
+			return
+		}
+	}
+
 	res, err := json.Marshal(payload)
 	if err != nil {
 		log.Print(err.Error())
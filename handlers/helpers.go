@@ -23,11 +23,11 @@ func paginateValues(r *http.Request) []*int32 {
 		hlog.Debug().Msg("Parse limit - " + err.Error())
 		hlog.Info().Msg("Invalid limit value. Using default")
 	} else {
-		if l < 1000 || l > 0 {
+		if l > 0 && l <= 1000 {
 			lo := int32(l)
 			res[0] = &lo
 		} else {
-			hlog.Info().Msg("Value of limit value out of range 0 - 1000")
+			hlog.Info().Msg("Value of limit value out of range 1 - 1000")
 		}
 	}
 
@@ -47,6 +47,18 @@ func paginateValues(r *http.Request) []*int32 {
 	return res
 }
 
+// Placeholder of secret value in responses
+const secretMask = "********"
+
+// Mask secret value. Empty value stays empty
+func maskSecret(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return secretMask
+}
+
 // Time filter
 func parseTimeFilter(r *http.Request) []time.Time {
 	res := make([]time.Time, 4)
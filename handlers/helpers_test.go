@@ -0,0 +1,22 @@
+package handlers
+
+import "testing"
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"secret", secretMask},
+		{"x", secretMask},
+		{secretMask, secretMask},
+		{"AAECAwQFBgcICQoLDA0ODw==", secretMask},
+	}
+
+	for _, tt := range tests {
+		if got := maskSecret(tt.in); got != tt.want {
+			t.Errorf("maskSecret(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
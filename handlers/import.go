@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+)
+
+// Maximum number of data rows in import request
+const importMaxRows = 10000
+
+// Import specification of resource
+type importSpec struct {
+	bulk bulkSpec
+	// ID column. Rows with ID are updated, rows without ID are created
+	key string
+	// JSON object of create and update data. Defines types of columns
+	model reflect.Type
+}
+
+// Importable resources
+var importResources = map[string]importSpec{
+	"connections":     {connectionsBulk, "con_id", reflect.TypeOf(godevmandb.UpdateConnectionParams{})},
+	"custom_entities": {customEntitiesBulk, "cent_id", reflect.TypeOf(godevmandb.UpdateCustomEntityParams{})},
+	"device_licenses": {deviceLicensesBulk, "lic_id", reflect.TypeOf(godevmandb.UpdateDeviceLicenseParams{})},
+	"devices":         {devicesBulk, "dev_id", reflect.TypeOf(device{})},
+	"sites":           {sitesBulk, "site_id", reflect.TypeOf(godevmandb.UpdateSiteParams{})},
+	"vlans":           {vlansBulk, "v_id", reflect.TypeOf(godevmandb.UpdateVlanParams{})},
+}
+
+// Result of imported row
+type importRow struct {
+	Line   int    `json:"line"`
+	Op     string `json:"op"`
+	Status int    `json:"status"`
+	ID     int64  `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Response of import request. Changes are committed only if all rows succeed and it is not dry run
+type ImportResponse struct {
+	Committed bool        `json:"committed"`
+	DryRun    bool        `json:"dry_run"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Failed    int         `json:"failed"`
+	Rows      []importRow `json:"rows"`
+}
+
+// JSON value of CSV cell in column of type t. Empty cell is null
+func importValue(t reflect.Type, v string) (any, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return v, nil
+	case reflect.Bool:
+		return strconv.ParseBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := strconv.ParseInt(v, 10, t.Bits()); err != nil {
+			return nil, err
+		}
+		return json.Number(v), nil
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(v, t.Bits()); err != nil {
+			return nil, err
+		}
+		return json.Number(v), nil
+	}
+
+	// Timestamps and other values
+	if json.Valid([]byte(v)) {
+		return json.RawMessage(v), nil
+	}
+
+	return v, nil
+}
+
+// Import CSV record in transaction
+func (h *Handler) importRecord(tx pgx.Tx, r *http.Request, s importSpec, cols []string, rec []string) (importRow, error) {
+	res := importRow{Op: "create"}
+
+	data := make(map[string]any, len(cols))
+	for i, c := range cols {
+		v, err := importValue(s.model.Field(modelField(s.model, c)).Type, rec[i])
+		if err != nil {
+			res.Status = http.StatusBadRequest
+			return res, fmt.Errorf("Invalid %s value", c)
+		}
+
+		if c == s.key {
+			if v != nil {
+				res.Op = "update"
+				res.ID, _ = strconv.ParseInt(rec[i], 10, 64)
+			}
+			continue
+		}
+		data[c] = v
+	}
+
+	// Columns missing from CSV keep their values
+	if res.Op == "update" {
+		cur, err := h.auditRow(tx, s.bulk.audit, []string{strconv.FormatInt(res.ID, 10)})
+		if err != nil {
+			res.Status = http.StatusInternalServerError
+			return res, err
+		}
+		if cur == nil {
+			res.Status = http.StatusNotFound
+			return res, errRowNotFound
+		}
+
+		for k, v := range data {
+			cur[k] = v
+		}
+		data = cur
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		res.Status = http.StatusInternalServerError
+		return res, err
+	}
+
+	id, _, status, err := h.bulkApply(tx, r, s.bulk, bulkItem{Op: res.Op, ID: res.ID, Data: b})
+	res.Status = status
+	if err == nil {
+		res.ID = id
+	}
+
+	return res, err
+}
+
+// Import Resource
+// @Summary Import resource
+// @Description Create and update rows of resource from CSV payload in single transaction.
+// @Description First row contains column names (JSON fields of resource). Rows with ID column (eg. dev_id) are updated,
+// @Description other rows are created. Columns missing from CSV keep their values on update. Empty cell is null.
+// @Description All rows are processed and reported. If any row fails, no changes are committed.
+// @Description Resources: connections, custom_entities, device_licenses, devices, sites, vlans
+// @Tags import
+// @ID import
+// @Param resource path string true "resource"
+// @Param dry_run query bool false "validate and report rows without committing changes"
+// @Param Body body string true "CSV payload"
+// @Success 200 {object} ImportResponse
+// @Failure 400 {object} ImportResponse "Invalid request"
+// @Failure 401 {object} StatusResponse "Authentication required"
+// @Failure 404 {object} StatusResponse "Unknown resource"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /import/{resource} [POST]
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if requestUser(r) == nil {
+		RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	s, ok := importResources[chi.URLParam(r, "resource")]
+	if !ok {
+		RespondError(w, r, http.StatusNotFound, "Unknown resource")
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid dry_run value")
+			return
+		}
+		dryRun = b
+	}
+
+	// Header. Number of fields is checked by row, so rows with wrong number of fields
+	// are reported like other failed rows
+	cr := csv.NewReader(r.Body)
+	cr.FieldsPerRecord = -1
+	defer r.Body.Close()
+	cols, err := cr.Read()
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	seen := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		if modelField(s.model, c) < 0 || seen[c] {
+			RespondError(w, r, http.StatusBadRequest, "Invalid column "+c)
+			return
+		}
+		seen[c] = true
+	}
+
+	tx, err := h.db.Begin(h.ctx)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(h.ctx)
+
+	res := ImportResponse{DryRun: dryRun, Rows: []importRow{}}
+	for n := 0; ; n++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			out := importRow{Status: http.StatusBadRequest, Error: err.Error()}
+			res.Failed++
+
+			// Malformed row is reported and following rows are still checked.
+			// Reading of payload stops on other errors
+			var pe *csv.ParseError
+			if errors.As(err, &pe) && n < importMaxRows {
+				out.Line = pe.StartLine
+				res.Rows = append(res.Rows, out)
+				continue
+			}
+			res.Rows = append(res.Rows, out)
+			break
+		}
+
+		line, _ := cr.FieldPos(0)
+		if n >= importMaxRows {
+			res.Failed++
+			res.Rows = append(res.Rows, importRow{Line: line, Status: http.StatusBadRequest, Error: "Too many rows"})
+			break
+		}
+
+		if len(rec) != len(cols) {
+			res.Failed++
+			res.Rows = append(res.Rows, importRow{Line: line, Status: http.StatusBadRequest, Error: "Wrong number of fields"})
+			continue
+		}
+
+		// Failed row is rolled back to savepoint. Following rows are still checked
+		sp, err := tx.Begin(h.ctx)
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		out, err := h.importRecord(sp, r, s, cols, rec)
+		out.Line = line
+		if err != nil {
+			out.Error = err.Error()
+			res.Failed++
+			if err := sp.Rollback(h.ctx); err != nil {
+				RespondError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+		} else {
+			if out.Op == "create" {
+				res.Created++
+			} else {
+				res.Updated++
+			}
+			if err := sp.Commit(h.ctx); err != nil {
+				RespondError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		res.Rows = append(res.Rows, out)
+	}
+
+	if res.Failed > 0 {
+		RespondJSON(w, r, http.StatusBadRequest, res)
+		return
+	}
+
+	if !dryRun {
+		if err := tx.Commit(h.ctx); err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		res.Committed = true
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
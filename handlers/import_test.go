@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportValue(t *testing.T) {
+	tests := []struct {
+		name string
+		t    reflect.Type
+		v    string
+		want any
+		err  bool
+	}{
+		{"empty is null", reflect.TypeOf(""), "", nil, false},
+		{"empty number is null", reflect.TypeOf(int64(0)), "", nil, false},
+		{"string", reflect.TypeOf(""), "core-router", "core-router", false},
+		{"string pointer", reflect.TypeOf((*string)(nil)), "12", "12", false},
+		{"bool", reflect.TypeOf(false), "true", true, false},
+		{"bool pointer", reflect.TypeOf((*bool)(nil)), "0", false, false},
+		{"invalid bool", reflect.TypeOf(false), "yes", nil, true},
+		{"int64", reflect.TypeOf(int64(0)), "-42", json.Number("-42"), false},
+		{"int32 pointer", reflect.TypeOf((*int32)(nil)), "42", json.Number("42"), false},
+		{"int32 overflow", reflect.TypeOf(int32(0)), "3000000000", nil, true},
+		{"invalid int", reflect.TypeOf(int64(0)), "1.5", nil, true},
+		{"float", reflect.TypeOf(float64(0)), "59.43", json.Number("59.43"), false},
+		{"invalid float", reflect.TypeOf(float64(0)), "north", nil, true},
+		{"time", reflect.TypeOf(time.Time{}), "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z", false},
+		{"JSON value", reflect.TypeOf(time.Time{}), `"2024-01-02T03:04:05Z"`, json.RawMessage(`"2024-01-02T03:04:05Z"`), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importValue(tt.t, tt.v)
+			if (err != nil) != tt.err {
+				t.Fatalf("importValue(%v, %q) error = %v, want error %v", tt.t, tt.v, err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importValue(%v, %q) = %#v, want %#v", tt.t, tt.v, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,17 +11,45 @@ import (
 
 // Count Int Bw Stats
 // @Summary Count int bw stats
-// @Description Count number of int bw stats
+// @Description Count number of int bw stats matching filters
 // @Tags interfaces
 // @ID count-bw_stats
+// @Param if_group_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param to50in_le query int false "SQL '<=' operator value"
+// @Param to50in_ge query int false "SQL '>=' operator value"
+// @Param to75in_le query int false "SQL '<=' operator value"
+// @Param to75in_ge query int false "SQL '>=' operator value"
+// @Param to90in_le query int false "SQL '<=' operator value"
+// @Param to90in_ge query int false "SQL '>=' operator value"
+// @Param to100in_le query int false "SQL '<=' operator value"
+// @Param to100in_ge query int false "SQL '>=' operator value"
+// @Param to50out_le query int false "SQL '<=' operator value"
+// @Param to50out_ge query int false "SQL '>=' operator value"
+// @Param to75out_le query int false "SQL '<=' operator value"
+// @Param to75out_ge query int false "SQL '>=' operator value"
+// @Param to90out_le query int false "SQL '<=' operator value"
+// @Param to90out_ge query int false "SQL '>=' operator value"
+// @Param to100out_le query int false "SQL '<=' operator value"
+// @Param to100out_ge query int false "SQL '>=' operator value"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/bw_stats/count [GET]
 func (h *Handler) CountIntBwStats(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountIntBwStats(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &intBwStatsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +58,37 @@ func (h *Handler) CountIntBwStats(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of int bw stats
+var intBwStatsList = listSpec{
+	table:  "int_bw_stats",
+	keys:   []string{"bw_id"},
+	domain: domainOfInterface,
+	model:  godevmandb.IntBwStat{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"to50in_le", "to50in", filterLeNull},
+		{"to50in_ge", "to50in", filterGeNull},
+		{"to75in_le", "to75in", filterLeNull},
+		{"to75in_ge", "to75in", filterGeNull},
+		{"to90in_le", "to90in", filterLeNull},
+		{"to90in_ge", "to90in", filterGeNull},
+		{"to100in_le", "to100in", filterLeNull},
+		{"to100in_ge", "to100in", filterGeNull},
+		{"to50out_le", "to50out", filterLeNull},
+		{"to50out_ge", "to50out", filterGeNull},
+		{"to75out_le", "to75out", filterLeNull},
+		{"to75out_ge", "to75out", filterGeNull},
+		{"to90out_le", "to90out", filterLeNull},
+		{"to90out_ge", "to90out", filterGeNull},
+		{"to100out_le", "to100out", filterLeNull},
+		{"to100out_ge", "to100out", filterGeNull},
+		{"if_group_f", "if_group", filterILike},
+	},
+}
+
 // List int bw stats
 // @Summary List int bw stats
 // @Description List int bw stats info
@@ -53,118 +112,39 @@ func (h *Handler) CountIntBwStats(w http.ResponseWriter, r *http.Request) {
 // @Param to100out_le query int false "SQL '<=' operator value"
 // @Param to100out_ge query int false "SQL '>=' operator value"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.IntBwStat
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/bw_stats [GET]
 func (h *Handler) GetIntBwStats(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetIntBwStatsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("if_group_f"); v != "" {
-		p.IfGroupF = v
-	}
-
-	if v := r.FormValue("to50in_le"); v != "" {
-		p.To50inLe = &v
-	}
-
-	if v := r.FormValue("to50in_ge"); v != "" {
-		p.To50inGe = &v
-	}
-
-	if v := r.FormValue("to75in_le"); v != "" {
-		p.To75inLe = &v
-	}
-
-	if v := r.FormValue("to75in_ge"); v != "" {
-		p.To75inGe = &v
-	}
-
-	if v := r.FormValue("to90in_le"); v != "" {
-		p.To90inLe = &v
-	}
-
-	if v := r.FormValue("to90in_ge"); v != "" {
-		p.To90inGe = &v
-	}
-
-	if v := r.FormValue("to100in_le"); v != "" {
-		p.To100inLe = &v
-	}
-
-	if v := r.FormValue("to100in_ge"); v != "" {
-		p.To100inGe = &v
-	}
-
-	if v := r.FormValue("to50out_le"); v != "" {
-		p.To50outLe = &v
-	}
-
-	if v := r.FormValue("to50out_ge"); v != "" {
-		p.To50outGe = &v
-	}
-
-	if v := r.FormValue("to75out_le"); v != "" {
-		p.To75outLe = &v
-	}
-
-	if v := r.FormValue("to75out_ge"); v != "" {
-		p.To75outGe = &v
-	}
-
-	if v := r.FormValue("to90out_le"); v != "" {
-		p.To90outLe = &v
-	}
-
-	if v := r.FormValue("to90out_ge"); v != "" {
-		p.To90outGe = &v
-	}
-
-	if v := r.FormValue("to100out_le"); v != "" {
-		p.To100inLe = &v
-	}
-
-	if v := r.FormValue("to100out_ge"); v != "" {
-		p.To100inGe = &v
+	// Query DB
+	l, err := parseListRequest(r, &intBwStatsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetIntBwStats(h.ctx, p)
+	res, page, err := listRows[godevmandb.IntBwStat](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get IntBwStat
@@ -173,7 +153,10 @@ func (h *Handler) GetIntBwStats(w http.ResponseWriter, r *http.Request) {
 // @Tags interfaces
 // @ID get-int_bw_stat
 // @Param bw_id path string true "bw_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.IntBwStat
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid bw_id"
 // @Failure 404 {object} StatusResponse "Stat not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -238,10 +221,13 @@ func (h *Handler) CreateIntBwStat(w http.ResponseWriter, r *http.Request) {
 // @ID update-int_bw_stat
 // @Param bw_id path string true "bw_id"
 // @Param Body body godevmandb.UpdateIntBwStatParams true "JSON object of godevmandb.UpdateIntBwStatParams.<br />Ignored fields:<ul><li>bw_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.IntBwStat
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/bw_stats/{bw_id} [PUT]
 func (h *Handler) UpdateIntBwStat(w http.ResponseWriter, r *http.Request) {
@@ -271,16 +257,39 @@ func (h *Handler) UpdateIntBwStat(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch IntBwStat
+// @Summary Patch IntBwStat
+// @Description Partially update IntBwStat with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags interfaces
+// @ID patch-int_bw_stat
+// @Param bw_id path string true "bw_id"
+// @Param Body body godevmandb.UpdateIntBwStatParams true "JSON merge patch of godevmandb.UpdateIntBwStatParams.<br />Ignored fields:<ul><li>bw_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.IntBwStat
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/bw_stats/{bw_id} [PATCH]
+func (h *Handler) PatchIntBwStat(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetIntBwStat, h.UpdateIntBwStat)
+}
+
 // Delete IntBwStat
 // @Summary Delete IntBwStat
 // @Description Delete IntBwStat
 // @Tags interfaces
 // @ID delete-int_bw_stat
 // @Param bw_id path string true "bw_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid bw_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/bw_stats/{bw_id} [DELETE]
 func (h *Handler) DeleteIntBwStat(w http.ResponseWriter, r *http.Request) {
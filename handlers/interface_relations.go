@@ -0,0 +1,527 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// Interface with higher and lower layer interfaces in interface stack
+type ifStackNode struct {
+	Interface iface          `json:"interface"`
+	Higher    []*ifStackNode `json:"higher,omitempty"`
+	Lower     []*ifStackNode `json:"lower,omitempty"`
+}
+
+// Relation is stored as higher and/or lower layer interface of interface.
+// Lower and higher layer pairs of all relations
+const ifStackEdges = `SELECT if_id AS lower_id, if_id_up AS higher_id FROM interface_relations WHERE if_id_up IS NOT NULL
+	UNION
+	SELECT if_id_down, if_id FROM interface_relations WHERE if_id_down IS NOT NULL`
+
+// Maximum depth of interface stack
+const ifStackMaxDepth = 16
+
+// Lower and higher layer pairs reachable from interface upwards.
+// Path of visited interfaces breaks loops and depth is limited by $2
+const ifStackHigher = `WITH RECURSIVE edges AS (` + ifStackEdges + `),
+	stack AS (
+		SELECT lower_id, higher_id, ARRAY[lower_id, higher_id] AS path, 1 AS depth FROM edges WHERE lower_id = $1
+		UNION ALL
+		SELECT e.lower_id, e.higher_id, s.path || e.higher_id, s.depth + 1
+		  FROM edges e JOIN stack s ON e.lower_id = s.higher_id
+		 WHERE e.higher_id <> ALL(s.path) AND s.depth < $2
+	)
+	SELECT DISTINCT lower_id, higher_id FROM stack`
+
+// Lower and higher layer pairs reachable from interface downwards.
+// Path of visited interfaces breaks loops and depth is limited by $2
+const ifStackLower = `WITH RECURSIVE edges AS (` + ifStackEdges + `),
+	stack AS (
+		SELECT lower_id, higher_id, ARRAY[higher_id, lower_id] AS path, 1 AS depth FROM edges WHERE higher_id = $1
+		UNION ALL
+		SELECT e.lower_id, e.higher_id, s.path || e.lower_id, s.depth + 1
+		  FROM edges e JOIN stack s ON e.higher_id = s.lower_id
+		 WHERE e.lower_id <> ALL(s.path) AND s.depth < $2
+	)
+	SELECT DISTINCT lower_id, higher_id FROM stack`
+
+// Query interface stack pairs. Returns map of interface ID to related interface IDs
+func (h *Handler) ifStackPairs(query string, id int64, higher bool) (map[int64][]int64, error) {
+	rows, err := h.db.Query(h.ctx, query, id, ifStackMaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[int64][]int64)
+	for rows.Next() {
+		var lower, upper int64
+		if err := rows.Scan(&lower, &upper); err != nil {
+			return nil, err
+		}
+
+		if higher {
+			res[lower] = append(res[lower], upper)
+		} else {
+			res[upper] = append(res[upper], lower)
+		}
+	}
+
+	return res, rows.Err()
+}
+
+// Build interface stack tree of interface.
+// Interfaces in device domains not readable by request user are left out with their subtrees
+func (h *Handler) ifStack(r *http.Request, id int64) (*ifStackNode, error) {
+	higher, err := h.ifStackPairs(ifStackHigher, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	lower, err := h.ifStackPairs(ifStackLower, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Interfaces in stack
+	ids := []int64{id}
+	for _, m := range []map[int64][]int64{higher, lower} {
+		for _, l := range m {
+			ids = append(ids, l...)
+		}
+	}
+
+	cols := modelColumns(reflect.TypeOf(godevmandb.Interface{}))
+	rows, err := h.db.Query(h.ctx, "SELECT "+strings.Join(cols, ", ")+" FROM interfaces WHERE if_id = ANY($1)", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []iface{}
+	for rows.Next() {
+		s, err := scanModel[godevmandb.Interface](rows)
+		if err != nil {
+			return nil, err
+		}
+
+		a := iface{}
+		a.getValues(s)
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	list, err = authzFilter(h, r, domOfInterface, list, func(a iface) int64 { return a.IfID })
+	if err != nil {
+		return nil, err
+	}
+
+	ifs := make(map[int64]iface, len(list))
+	for _, a := range list {
+		ifs[a.IfID] = a
+	}
+
+	// Walk stack. Interfaces already on path are skipped to break loops
+	var walk func(id int64, m map[int64][]int64, up bool, path map[int64]bool) []*ifStackNode
+	walk = func(id int64, m map[int64][]int64, up bool, path map[int64]bool) []*ifStackNode {
+		path[id] = true
+		defer delete(path, id)
+
+		var out []*ifStackNode
+		for _, c := range m[id] {
+			a, ok := ifs[c]
+			if !ok || path[c] {
+				continue
+			}
+
+			n := &ifStackNode{Interface: a}
+			if up {
+				n.Higher = walk(c, m, up, path)
+			} else {
+				n.Lower = walk(c, m, up, path)
+			}
+			out = append(out, n)
+		}
+
+		return out
+	}
+
+	a, ok := ifs[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &ifStackNode{
+		Interface: a,
+		Higher:    walk(id, higher, true, map[int64]bool{}),
+		Lower:     walk(id, lower, false, map[int64]bool{}),
+	}, nil
+}
+
+// Count InterfaceRelations
+// @Summary Count interface_relations
+// @Description Count number of interface_relations matching filters
+// @Tags interfaces
+// @ID count-interface_relations
+// @Param if_id_f query string false "SQL '=' operator value"
+// @Param if_id_up_f query string false "SQL '=' operator value + special value 'isnull'"
+// @Param if_id_down_f query string false "SQL '=' operator value + special value 'isnull'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/count [GET]
+func (h *Handler) CountInterfaceRelations(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &interfaceRelationsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List query specification of interface relations
+var interfaceRelationsList = listSpec{
+	table:  "interface_relations",
+	keys:   []string{"ir_id"},
+	domain: domainOfInterface,
+	model:  godevmandb.InterfaceRelation{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"if_id_f", "if_id", filterEq},
+		{"if_id_up_f", "if_id_up", filterEqNull},
+		{"if_id_down_f", "if_id_down", filterEqNull},
+	},
+}
+
+// List interface_relations
+// @Summary List interface_relations
+// @Description List interface_relations info
+// @Tags interfaces
+// @ID list-interface_relations
+// @Param if_id_f query string false "SQL '=' operator value"
+// @Param if_id_up_f query string false "SQL '=' operator value + special value 'isnull'"
+// @Param if_id_down_f query string false "SQL '=' operator value + special value 'isnull'"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} godevmandb.InterfaceRelation
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations [GET]
+func (h *Handler) GetInterfaceRelations(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &interfaceRelationsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[godevmandb.InterfaceRelation](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get InterfaceRelation
+// @Summary Get interface_relation
+// @Description Get interface_relation info
+// @Tags interfaces
+// @ID get-interface_relation
+// @Param ir_id path string true "ir_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} godevmandb.InterfaceRelation
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid ir_id"
+// @Failure 404 {object} StatusResponse "Relation not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id} [GET]
+func (h *Handler) GetInterfaceRelation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetInterfaceRelation(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Relation not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Create InterfaceRelation
+// @Summary Create interface_relation
+// @Description Create interface_relation. if_id_up is higher and if_id_down is lower layer interface of if_id
+// @Tags interfaces
+// @ID create-interface_relation
+// @Param Body body godevmandb.CreateInterfaceRelationParams true "JSON object of godevmandb.CreateInterfaceRelationParams"
+// @Success 201 {object} godevmandb.InterfaceRelation
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations [POST]
+func (h *Handler) CreateInterfaceRelation(w http.ResponseWriter, r *http.Request) {
+	var p godevmandb.CreateInterfaceRelationParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	q := godevmandb.New(h.db)
+	res, err := q.CreateInterfaceRelation(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, res)
+}
+
+// Update InterfaceRelation
+// @Summary Update interface_relation
+// @Description Update interface_relation
+// @Tags interfaces
+// @ID update-interface_relation
+// @Param ir_id path string true "ir_id"
+// @Param Body body godevmandb.UpdateInterfaceRelationParams true "JSON object of godevmandb.UpdateInterfaceRelationParams.<br />Ignored fields:<ul><li>ir_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.InterfaceRelation
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id} [PUT]
+func (h *Handler) UpdateInterfaceRelation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	var p godevmandb.UpdateInterfaceRelationParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	p.IrID = id
+
+	q := godevmandb.New(h.db)
+	res, err := q.UpdateInterfaceRelation(h.ctx, p)
+
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Patch InterfaceRelation
+// @Summary Patch interface_relation
+// @Description Partially update interface_relation with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags interfaces
+// @ID patch-interface_relation
+// @Param ir_id path string true "ir_id"
+// @Param Body body godevmandb.UpdateInterfaceRelationParams true "JSON merge patch of godevmandb.UpdateInterfaceRelationParams.<br />Ignored fields:<ul><li>ir_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.InterfaceRelation
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id} [PATCH]
+func (h *Handler) PatchInterfaceRelation(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetInterfaceRelation, h.UpdateInterfaceRelation)
+}
+
+// Delete InterfaceRelation
+// @Summary Delete interface_relation
+// @Description Delete interface_relation
+// @Tags interfaces
+// @ID delete-interface_relation
+// @Param ir_id path string true "ir_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid ir_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id} [DELETE]
+func (h *Handler) DeleteInterfaceRelation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	err = q.DeleteInterfaceRelation(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Foreign key
+// Get InterfaceRelation Interface
+// @Summary Get interface_relation interface
+// @Description Get interface_relation interface info
+// @Tags interfaces
+// @ID get-interface_relation-interface
+// @Param ir_id path string true "ir_id"
+// @Success 200 {object} iface
+// @Failure 400 {object} StatusResponse "Invalid ir_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id}/interface [GET]
+func (h *Handler) GetInterfaceRelationInterface(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetInterfaceRelationInterface(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := iface{}
+	out.getValues(res)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Foreign key
+// Get InterfaceRelation Higher Interface
+// @Summary Get interface_relation higher interface
+// @Description Get interface_relation higher layer interface info
+// @Tags interfaces
+// @ID get-interface_relation-interface_up
+// @Param ir_id path string true "ir_id"
+// @Success 200 {object} iface
+// @Failure 400 {object} StatusResponse "Invalid ir_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id}/interface_up [GET]
+func (h *Handler) GetInterfaceRelationInterfaceUp(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetInterfaceRelationInterfaceUp(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := iface{}
+	out.getValues(res)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Foreign key
+// Get InterfaceRelation Lower Interface
+// @Summary Get interface_relation lower interface
+// @Description Get interface_relation lower layer interface info
+// @Tags interfaces
+// @ID get-interface_relation-interface_down
+// @Param ir_id path string true "ir_id"
+// @Success 200 {object} iface
+// @Failure 400 {object} StatusResponse "Invalid ir_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/relations/{ir_id}/interface_down [GET]
+func (h *Handler) GetInterfaceRelationInterfaceDown(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ir_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface relation ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetInterfaceRelationInterfaceDown(h.ctx, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := iface{}
+	out.getValues(res)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
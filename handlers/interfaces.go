@@ -111,17 +111,40 @@ func (r *iface) updateParams() godevmandb.UpdateInterfaceParams {
 
 // Count Interfaces
 // @Summary Count interfaces
-// @Description Count number of interfaces
+// @Description Count number of interfaces matching filters
 // @Tags interfaces
 // @ID count-interfaces
+// @Param ifindex_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param oper_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param adm_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param speed_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param minspeed_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param type_enum_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param mac_f query string false "SQL '=' operator value (MAC address)"
+// @Param monstatus_f query bool false "values 'true', 'false'"
+// @Param monerrors_f query bool false "values 'true', 'false'"
+// @Param monload_f query bool false "values 'true', 'false'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/count [GET]
 func (h *Handler) CountInterfaces(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountInterfaces(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &interfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -130,6 +153,32 @@ func (h *Handler) CountInterfaces(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of interfaces
+var interfacesList = listSpec{
+	table:  "interfaces",
+	keys:   []string{"if_id"},
+	domain: domainOfDevChild,
+	model:  iface{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"ifindex_f", "ifindex", filterLikeNull},
+		{"descr_f", "descr", filterILike},
+		{"alias_f", "alias", filterILikeNull},
+		{"oper_f", "oper", filterLikeNull},
+		{"adm_f", "adm", filterLikeNull},
+		{"speed_f", "speed", filterLikeNull},
+		{"minspeed_f", "minspeed", filterLikeNull},
+		{"type_enum_f", "type_enum", filterLikeNull},
+		{"mac_f", "mac", filterMacaddr},
+		{"monstatus_f", "monstatus", filterEq},
+		{"monerrors_f", "monerrors", filterEq},
+		{"monload_f", "monload", filterEq},
+	},
+}
+
 // List interfaces
 // @Summary List interfaces
 // @Description List interfaces info
@@ -148,93 +197,33 @@ func (h *Handler) CountInterfaces(w http.ResponseWriter, r *http.Request) {
 // @Param monerrors_f query bool false "values 'true', 'false'"
 // @Param monload_f query bool false "values 'true', 'false'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} iface
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces [GET]
 func (h *Handler) GetInterfaces(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetInterfacesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("ifindex_f"); v != "" {
-		p.IfindexF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("alias_f"); v != "" {
-		p.AliasF = &v
-	}
-
-	if v := r.FormValue("oper_f"); v != "" {
-		p.OperF = &v
-	}
-
-	if v := r.FormValue("adm_f"); v != "" {
-		p.AdmF = &v
-	}
-
-	if v := r.FormValue("speed_f"); v != "" {
-		p.SpeedF = &v
-	}
-
-	if v := r.FormValue("minspeed_f"); v != "" {
-		p.MinspeedF = &v
-	}
-
-	if v := r.FormValue("type_enum_f"); v != "" {
-		p.TypeEnumF = &v
-	}
-
-	p.MacF = strToPgMacaddr(nil)
-	if v := r.FormValue("mac_f"); v != "" {
-		p.MacF = strToPgMacaddr(&v)
-	}
-
-	if v := r.FormValue("monstatus_f"); v != "" {
-		p.MonstatusF = v
-	}
-
-	if v := r.FormValue("monerrors_f"); v != "" {
-		p.MonerrorsF = v
-	}
-
-	if v := r.FormValue("monload_f"); v != "" {
-		p.MonloadF = v
+	// Query DB
+	l, err := parseListRequest(r, &interfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetInterfaces(h.ctx, p)
+	res, page, err := listRows[godevmandb.Interface](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -247,7 +236,7 @@ func (h *Handler) GetInterfaces(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get Interface
@@ -256,7 +245,10 @@ func (h *Handler) GetInterfaces(w http.ResponseWriter, r *http.Request) {
 // @Tags interfaces
 // @ID get-interface
 // @Param if_id path string true "if_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} iface
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid if_id"
 // @Failure 404 {object} StatusResponse "Interface not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -331,10 +323,13 @@ func (h *Handler) CreateInterface(w http.ResponseWriter, r *http.Request) {
 // @ID update-interface
 // @Param if_id path string true "if_id"
 // @Param Body body iface true "JSON object of iface.<br />Ignored fields:<ul><li>if_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} iface
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/{if_id} [PUT]
 func (h *Handler) UpdateInterface(w http.ResponseWriter, r *http.Request) {
@@ -370,16 +365,39 @@ func (h *Handler) UpdateInterface(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch Interface
+// @Summary Patch interface
+// @Description Partially update interface with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags interfaces
+// @ID patch-interface
+// @Param if_id path string true "if_id"
+// @Param Body body iface true "JSON merge patch of iface.<br />Ignored fields:<ul><li>if_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} iface
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/{if_id} [PATCH]
+func (h *Handler) PatchInterface(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetInterface, h.UpdateInterface)
+}
+
 // Delete Interface
 // @Summary Delete interface
 // @Description Delete interface
 // @Tags interfaces
 // @ID delete-interface
 // @Param if_id path string true "if_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid if_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/{if_id} [DELETE]
 func (h *Handler) DeleteInterface(w http.ResponseWriter, r *http.Request) {
@@ -399,6 +417,67 @@ func (h *Handler) DeleteInterface(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of interfaces
+var interfacesBulk = bulkSpec{
+	scope: ScopeInterface,
+	audit: AuditInterface,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var pIn iface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateInterface(h.ctx, pIn.createParams())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		out := iface{}
+		out.getValues(res)
+
+		return res.IfID, out, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var pIn iface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return nil, err
+		}
+
+		p := pIn.updateParams()
+		p.IfID = id
+
+		res, err := q.UpdateInterface(h.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		out := iface{}
+		out.getValues(res)
+
+		return out, nil
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteInterface(h.ctx, id)
+	},
+}
+
+// Bulk Interfaces
+// @Summary Bulk change interfaces
+// @Description Create, update and delete interfaces in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags interfaces
+// @ID bulk-interfaces
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create and update items is JSON object of iface"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /interfaces/bulk [POST]
+func (h *Handler) BulkInterfaces(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, interfacesBulk)
+}
+
 // Foreign key
 // Get Interface Connection
 // @Summary Get interface connection
@@ -699,6 +778,40 @@ func (h *Handler) GetInterfaceInterfaceRelationsLowerFor(w http.ResponseWriter,
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Relations
+// Get Interface Stack
+// @Summary Get interface stack
+// @Description Get recursive tree of higher and lower layer interfaces of interface (ifStackTable).
+// @Description Tree depth is limited to 16 levels. Interfaces in device domains not readable by user are left out
+// @Tags interfaces
+// @ID get-interface-stack
+// @Param if_id path string true "if_id"
+// @Success 200 {object} ifStackNode
+// @Failure 400 {object} StatusResponse "Invalid if_id"
+// @Failure 404 {object} StatusResponse "Interface not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/{if_id}/stack [GET]
+func (h *Handler) GetInterfaceStack(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "if_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface ID")
+		return
+	}
+
+	res, err := h.ifStack(r, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if res == nil {
+		RespondError(w, r, http.StatusNotFound, "Interface not found")
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
 // Relations
 // List Interface Vlans
 // @Summary List interface vlans
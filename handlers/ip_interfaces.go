@@ -62,17 +62,32 @@ func (r *ipInterface) updateParams() godevmandb.UpdateIpInterfaceParams {
 
 // Count IpInterfaces
 // @Summary Count ip_interfaces
-// @Description Count number of ip_interfaces
+// @Description Count number of ip_interfaces matching filters
 // @Tags ip_interfaces
 // @ID count-ip_interfaces
+// @Param ifindex_f query string false "url encoded SQL 'LIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param ip_addr_f query string false "ip or containing net in CIDR notation"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /ip_interfaces/count [GET]
 func (h *Handler) CountIpInterfaces(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountIpInterfaces(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &ipInterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -81,6 +96,24 @@ func (h *Handler) CountIpInterfaces(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of ip interfaces
+var ipInterfacesList = listSpec{
+	table:  "ip_interfaces",
+	keys:   []string{"ip_id"},
+	domain: domainOfDevChild,
+	model:  ipInterface{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"ifindex_f", "ifindex", filterLikeNull},
+		{"descr_f", "descr", filterILikeNull},
+		{"alias_f", "alias", filterILikeNull},
+		{"ip_addr_f", "ip_addr", filterInet},
+	},
+}
+
 // List ip_interfaces
 // @Summary List ip_interfaces
 // @Description List ip_interfaces info
@@ -91,61 +124,33 @@ func (h *Handler) CountIpInterfaces(w http.ResponseWriter, r *http.Request) {
 // @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
 // @Param ip_addr_f query string false "ip or containing net in CIDR notation"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} ipInterface
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /ip_interfaces [GET]
 func (h *Handler) GetIpInterfaces(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetIpInterfacesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("ifindex_f"); v != "" {
-		p.IfindexF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
-	}
-
-	if v := r.FormValue("alias_f"); v != "" {
-		p.AliasF = &v
-	}
-
-	p.IpAddrF = strToPgInet(nil)
-	if v := r.FormValue("ip_addr_f"); v != "" {
-		p.IpAddrF = strToPgInet(&v)
+	// Query DB
+	l, err := parseListRequest(r, &ipInterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetIpInterfaces(h.ctx, p)
+	res, page, err := listRows[godevmandb.IpInterface](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -158,7 +163,7 @@ func (h *Handler) GetIpInterfaces(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get IpInterface
@@ -167,7 +172,10 @@ func (h *Handler) GetIpInterfaces(w http.ResponseWriter, r *http.Request) {
 // @Tags ip_interfaces
 // @ID get-ip_interface
 // @Param ip_id path string true "ip_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} ipInterface
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid ip_id"
 // @Failure 404 {object} StatusResponse "IpInterface not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -242,10 +250,13 @@ func (h *Handler) CreateIpInterface(w http.ResponseWriter, r *http.Request) {
 // @ID update-ip_interface
 // @Param ip_id path string true "ip_id"
 // @Param Body body ipInterface true "JSON object of ipInterface.<br />Ignored fields:<ul><li>ip_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} ipInterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /ip_interfaces/{ip_id} [PUT]
 func (h *Handler) UpdateIpInterface(w http.ResponseWriter, r *http.Request) {
@@ -281,16 +292,39 @@ func (h *Handler) UpdateIpInterface(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch IpInterface
+// @Summary Patch ip_interface
+// @Description Partially update ip_interface with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags ip_interfaces
+// @ID patch-ip_interface
+// @Param ip_id path string true "ip_id"
+// @Param Body body ipInterface true "JSON merge patch of ipInterface.<br />Ignored fields:<ul><li>ip_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} ipInterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /ip_interfaces/{ip_id} [PATCH]
+func (h *Handler) PatchIpInterface(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetIpInterface, h.UpdateIpInterface)
+}
+
 // Delete IpInterface
 // @Summary Delete ip_interface
 // @Description Delete ip_interface
 // @Tags ip_interfaces
 // @ID delete-ip_interface
 // @Param ip_id path string true "ip_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid ip_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /ip_interfaces/{ip_id} [DELETE]
 func (h *Handler) DeleteIpInterface(w http.ResponseWriter, r *http.Request) {
@@ -310,6 +344,67 @@ func (h *Handler) DeleteIpInterface(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of ip interfaces
+var ipInterfacesBulk = bulkSpec{
+	scope: ScopeIpInterface,
+	audit: AuditIpInterface,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var pIn ipInterface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateIpInterface(h.ctx, pIn.createParams())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		out := ipInterface{}
+		out.getValues(res)
+
+		return res.IpID, out, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var pIn ipInterface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return nil, err
+		}
+
+		p := pIn.updateParams()
+		p.IpID = id
+
+		res, err := q.UpdateIpInterface(h.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		out := ipInterface{}
+		out.getValues(res)
+
+		return out, nil
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteIpInterface(h.ctx, id)
+	},
+}
+
+// Bulk IpInterfaces
+// @Summary Bulk change ip_interfaces
+// @Description Create, update and delete ip_interfaces in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags ip_interfaces
+// @ID bulk-ip_interfaces
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create and update items is JSON object of ipInterface"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /ip_interfaces/bulk [POST]
+func (h *Handler) BulkIpInterfaces(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, ipInterfacesBulk)
+}
+
 // Foreign key
 // Get IpInterface Device
 // @Summary Get ip_interface device
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/jackc/pgx/v4"
+)
+
+// Encryption keys. New values are encrypted with current salt.
+// Values encrypted with previous salt are readable during key rotation.
+// API token hashes use separate token key which is not rotated
+var keys struct {
+	mu       sync.RWMutex
+	salt     string
+	oldSalt  string
+	tokenKey string
+}
+
+// Set encryption keys. Keys are changed only in this process,
+// other API instances use their configured keys until restart
+func setKeys(cur, old string) {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	keys.salt = cur
+	keys.oldSalt = old
+}
+
+// Return current and previous encryption keys
+func getKeys() (string, string) {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	return keys.salt, keys.oldSalt
+}
+
+// Set key of API token hashes
+func setTokenKey(k string) {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	keys.tokenKey = k
+}
+
+// Return key of API token hashes. Current encryption key is used if token key is not set
+func getTokenKey() string {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	if keys.tokenKey == "" {
+		return keys.salt
+	}
+
+	return keys.tokenKey
+}
+
+// Encrypt secret with current key
+func encryptSecret(s string) (string, error) {
+	cur, _ := getKeys()
+
+	return godevmandb.EncryptStrAes(s, cur)
+}
+
+// Decrypt secret with current or previous key
+func decryptSecret(s string) (string, error) {
+	cur, old := getKeys()
+
+	return decryptWithKeys(s, cur, old)
+}
+
+// Decrypt secret with first working key from list. Empty keys are skipped
+func decryptWithKeys(s string, k ...string) (string, error) {
+	var err error = errors.New("no encryption key")
+	for _, key := range k {
+		if key == "" {
+			continue
+		}
+
+		var val string
+		val, err = godevmandb.DecryptStrAes(s, key)
+		if err == nil {
+			return val, nil
+		}
+	}
+
+	return "", err
+}
+
+// Number of re-encrypted rows per table
+type keyRotation struct {
+	Credentials       int64 `json:"credentials"`
+	DeviceCredentials int64 `json:"device_credentials"`
+	SnmpCredentials   int64 `json:"snmp_credentials"`
+}
+
+// Input parameters of key rotation
+type keyRotationParams struct {
+	NewSalt string `json:"new_salt"`
+}
+
+// Re-encrypt secret with new key
+func reEncrypt(s, newSalt, cur, old string) (string, error) {
+	val, err := decryptWithKeys(s, newSalt, cur, old)
+	if err != nil {
+		return "", err
+	}
+
+	return godevmandb.EncryptStrAes(val, newSalt)
+}
+
+// Re-encrypt secret columns of table in transaction.
+// Query must return row ID and secret columns. Update statement gets row ID and secret columns as arguments
+func (h *Handler) reEncryptTable(tx pgx.Tx, query, update string, newSalt string) (int64, error) {
+	type row struct {
+		vals []*string
+		id   int64
+	}
+
+	cur, old := getKeys()
+
+	rows, err := tx.Query(h.ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var res []row
+	for rows.Next() {
+		a := row{vals: make([]*string, len(rows.FieldDescriptions())-1)}
+		dst := []any{&a.id}
+		for i := range a.vals {
+			dst = append(dst, &a.vals[i])
+		}
+		if err := rows.Scan(dst...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		res = append(res, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, a := range res {
+		args := []any{a.id}
+		for _, v := range a.vals {
+			if v == nil || *v == "" {
+				args = append(args, v)
+				continue
+			}
+
+			val, err := reEncrypt(*v, newSalt, cur, old)
+			if err != nil {
+				return 0, fmt.Errorf("row %d: %w", a.id, err)
+			}
+			args = append(args, &val)
+		}
+
+		if _, err := tx.Exec(h.ctx, update, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(res)), nil
+}
+
+// Re-encrypt all secrets with new key in one transaction.
+// On success new key becomes current and previous current key stays readable in this process.
+// API token hashes are not affected as they use token key
+func (h *Handler) RotateKey(newSalt string) (keyRotation, error) {
+	out := keyRotation{}
+	if newSalt == "" {
+		return out, errors.New("empty encryption key")
+	}
+	if h.inTx() {
+		return out, errors.New("key rotation is not allowed in transaction")
+	}
+
+	// Token hashes can't be rehashed without tokens. They must not depend on rotated key
+	keys.mu.RLock()
+	tk := keys.tokenKey
+	keys.mu.RUnlock()
+	if tk == "" {
+		return out, errors.New("token key is not configured. Set TokenKey to current Salt before rotation")
+	}
+
+	tx, err := h.db.Begin(h.ctx)
+	if err != nil {
+		return out, err
+	}
+	defer tx.Rollback(h.ctx)
+
+	out.Credentials, err = h.reEncryptTable(tx,
+		`SELECT cred_id, enc_secret FROM credentials FOR UPDATE`,
+		`UPDATE credentials SET enc_secret = $2 WHERE cred_id = $1`,
+		newSalt)
+	if err != nil {
+		return out, fmt.Errorf("credentials: %w", err)
+	}
+
+	out.DeviceCredentials, err = h.reEncryptTable(tx,
+		`SELECT cred_id, enc_secret FROM device_credentials FOR UPDATE`,
+		`UPDATE device_credentials SET enc_secret = $2 WHERE cred_id = $1`,
+		newSalt)
+	if err != nil {
+		return out, fmt.Errorf("device_credentials: %w", err)
+	}
+
+	out.SnmpCredentials, err = h.reEncryptTable(tx,
+		`SELECT snmp_cred_id, auth_pass, priv_pass FROM snmp_credentials FOR UPDATE`,
+		`UPDATE snmp_credentials SET auth_pass = $2, priv_pass = $3 WHERE snmp_cred_id = $1`,
+		newSalt)
+	if err != nil {
+		return out, fmt.Errorf("snmp_credentials: %w", err)
+	}
+
+	if err := tx.Commit(h.ctx); err != nil {
+		return out, err
+	}
+
+	cur, _ := getKeys()
+	setKeys(newSalt, cur)
+
+	return out, nil
+}
+
+// Rotate Encryption Key
+// @Summary Rotate encryption key
+// @Description Re-encrypt all credential secrets with new key in one transaction.
+// @Description Previous key stays readable until restart. Keys are changed only in API instance serving the request.
+// @Description Configuration of all instances must be updated with new key (Salt) and previous key (OldSalt) and other instances restarted.
+// @Description Requires TokenKey configuration option
+// @Tags config
+// @ID rotate-key
+// @Param Body body keyRotationParams true "JSON object of keyRotationParams"
+// @Success 200 {object} keyRotation
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/rotate_key [POST]
+func (h *Handler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	var p keyRotationParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil || p.NewSalt == "" {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	out, err := h.RotateKey(p.NewSalt)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.audit(r, "rotate_key", "encryption_key", ""); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
@@ -0,0 +1,760 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+)
+
+// Kinds of list filter query parameters
+type filterKind int
+
+const (
+	filterILike      filterKind = iota // SQL 'ILIKE' pattern
+	filterILikeEmpty                   // SQL 'ILIKE' pattern + special value 'isempty'
+	filterILikeNull                    // SQL 'ILIKE' pattern + special values 'isnull', 'isempty'
+	filterLike                         // SQL 'LIKE' pattern on text value of column
+	filterLikeNull                     // SQL 'LIKE' pattern on text value of column + special values 'isnull', 'isempty'
+	filterEq                           // SQL '=' value on text value of column
+	filterEqNull                       // SQL '=' value on text value of column + special value 'isnull'
+	filterBool                         // values 'true', 'false'
+	filterInet                         // ip or containing net in CIDR notation
+	filterMacaddr                      // SQL '=' MAC address
+	filterGe                           // SQL '>=' integer
+	filterGeNull                       // SQL '>=' integer + special value 'isnull'
+	filterLe                           // SQL '<=' integer
+	filterLeNull                       // SQL '<=' integer + special value 'isnull'
+	filterTimeGe                       // time >= unix timestamp in milliseconds
+	filterTimeLe                       // time <= unix timestamp in milliseconds
+)
+
+// List filter query parameter
+type listFilter struct {
+	param string
+	// Table column or SQL expression of row "t"
+	column string
+	kind   filterKind
+}
+
+// SQL expression of filtered value
+func (f listFilter) expr() string {
+	if strings.ContainsAny(f.column, " (") {
+		return f.column
+	}
+
+	return "t." + f.column
+}
+
+// List query specification of resource
+type listSpec struct {
+	// Table name
+	table string
+	// Primary key columns. List is ordered by primary key
+	keys []string
+	// SQL expression of device domain ID of row "t". Empty if resource is not in device domain
+	domain string
+	// Fixed SQL condition of row "t". Empty if all table rows belong to resource
+	where string
+	// Query parameter filters
+	filters []listFilter
+	// Parser of resource specific filter parameters. Optional
+	params func(r *http.Request, l *listRequest) error
+	// Response model. JSON fields of model are sort fields
+	model interface{}
+	// Table columns of model JSON fields which differ from field name. Empty column makes field unsortable
+	columns map[string]string
+	// Default sort parameter. Empty for primary key order
+	sort string
+}
+
+// Table column of sort field. Returns empty string if field is not sortable
+func (s *listSpec) sortColumn(field string) string {
+	t := reflect.TypeOf(s.model)
+	if modelField(t, field) < 0 {
+		return ""
+	}
+	if c, ok := s.columns[field]; ok {
+		return c
+	}
+
+	return field
+}
+
+// SQL expressions of device domain ID of row "t"
+const (
+	domainOfDevice    = `t.dom_id`
+	domainOfDevChild  = `(SELECT d.dom_id FROM devices d WHERE d.dev_id = t.dev_id)`
+	domainOfInterface = `(SELECT d.dom_id FROM interfaces i JOIN devices d ON d.dev_id = i.dev_id WHERE i.if_id = t.if_id)`
+	domainOfEntity    = `(SELECT d.dom_id FROM entities e JOIN devices d ON d.dev_id = e.dev_id WHERE e.ent_id = t.ent_id)`
+)
+
+// Position in list. Values are order column values of boundary row
+type listCursor struct {
+	Sort   string            `json:"s,omitempty"`
+	Values []json.RawMessage `json:"v"`
+	Prev   bool              `json:"p,omitempty"`
+}
+
+// Encode cursor to opaque string
+func (c listCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode opaque cursor string
+func decodeCursor(s string) (*listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(listCursor)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Cursors of neighbour pages. Empty cursor means, there is no such page
+type listPage struct {
+	Next   string
+	Prev   string
+	Total  int64
+	Limit  int32
+	Offset int32
+	// Respond with ListResponse envelope
+	envelope bool
+}
+
+// List response envelope
+type ListResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+	Total      int64       `json:"total"`
+	Limit      int32       `json:"limit"`
+	Offset     int32       `json:"offset"`
+}
+
+// Profile of Accept header media type which selects list response envelope
+const envelopeProfile = "envelope"
+
+// Check if list response envelope is requested by "envelope" query parameter
+// or by Accept header profile. Eg. 'Accept: application/json; profile="envelope"'
+func wantEnvelope(r *http.Request) bool {
+	if v := r.FormValue("envelope"); v != "" {
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err == nil && params["profile"] == envelopeProfile {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DB query interface of pool and transaction
+type listQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Parsed list request
+type listRequest struct {
+	spec     *listSpec
+	cursor   *listCursor
+	where    []string
+	args     []any
+	limit    int32
+	offset   int32
+	envelope bool
+	// Normalized sort parameter
+	sort string
+	// Order columns. Sort columns followed by primary key columns
+	order []orderColumn
+}
+
+// List order column
+type orderColumn struct {
+	column string
+	desc   bool
+}
+
+// Parse sort parameter like "field,-field2". Fields are sorted in ascending
+// order by default and descending if prefixed with "-". Primary key columns
+// are added to make order unique
+func (l *listRequest) parseSort(v string) error {
+	seen := map[string]bool{}
+	fields := []string{}
+
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		desc := strings.HasPrefix(f, "-")
+		f = strings.TrimLeft(f, "+-")
+
+		c := l.spec.sortColumn(f)
+		if c == "" {
+			return fmt.Errorf("Invalid sort field %q", f)
+		}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		l.order = append(l.order, orderColumn{column: c, desc: desc})
+		if desc {
+			f = "-" + f
+		}
+		fields = append(fields, f)
+	}
+	l.sort = strings.Join(fields, ",")
+
+	for _, k := range l.spec.keys {
+		if !seen[k] {
+			l.order = append(l.order, orderColumn{column: k})
+		}
+	}
+
+	return nil
+}
+
+// Order columns
+func (l *listRequest) orderColumns() []string {
+	res := make([]string, 0, len(l.order))
+	for _, o := range l.order {
+		res = append(res, o.column)
+	}
+
+	return res
+}
+
+// Add SQL condition. %d in condition is replaced with placeholder number of argument
+func (l *listRequest) add(cond string, arg any) {
+	l.args = append(l.args, arg)
+	l.where = append(l.where, fmt.Sprintf(cond, len(l.args)))
+}
+
+// Add SQL condition with several arguments. %[n]d in condition is replaced with placeholder number of n-th argument
+func (l *listRequest) addN(cond string, args ...any) {
+	nums := make([]any, 0, len(args))
+	for _, a := range args {
+		l.args = append(l.args, a)
+		nums = append(nums, len(l.args))
+	}
+	l.where = append(l.where, fmt.Sprintf(cond, nums...))
+}
+
+// Add filter condition from query parameter value
+func (l *listRequest) addFilter(f listFilter, v string) error {
+	col := f.expr()
+	text := "CAST(" + col + " AS text)"
+
+	switch f.kind {
+	case filterILike:
+		l.add(col+" ILIKE $%d", v)
+	case filterILikeEmpty, filterILikeNull, filterLikeNull:
+		switch {
+		case v == "isnull" && f.kind != filterILikeEmpty:
+			l.where = append(l.where, col+" IS NULL")
+		case v == "isempty" && f.kind == filterLikeNull:
+			l.where = append(l.where, text+" = ''")
+		case v == "isempty":
+			l.where = append(l.where, col+" = ''")
+		case f.kind == filterLikeNull:
+			l.add(text+" LIKE $%d", v)
+		default:
+			l.add(col+" ILIKE $%d", v)
+		}
+	case filterLike:
+		l.add(text+" LIKE $%d", v)
+	case filterEq:
+		l.add(text+" = $%d", v)
+	case filterEqNull:
+		if v == "isnull" {
+			l.where = append(l.where, col+" IS NULL")
+		} else {
+			l.add(text+" = $%d", v)
+		}
+	case filterBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		l.add(col+" = $%d", b)
+	case filterInet:
+		n := strToPgInet(&v)
+		if n.Status != pgtype.Present {
+			return errors.New("invalid address")
+		}
+		l.add(col+" <<= $%d", n)
+	case filterMacaddr:
+		m := strToPgMacaddr(&v)
+		if m.Status != pgtype.Present {
+			return errors.New("invalid MAC address")
+		}
+		l.add(col+" = $%d", m)
+	case filterGe, filterGeNull, filterLe, filterLeNull:
+		if v == "isnull" && (f.kind == filterGeNull || f.kind == filterLeNull) {
+			l.where = append(l.where, col+" IS NULL")
+			return nil
+		}
+
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		op := " >= "
+		if f.kind == filterLe || f.kind == filterLeNull {
+			op = " <= "
+		}
+		l.add(col+op+"$%d", i)
+	case filterTimeGe, filterTimeLe:
+		uts, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		op := " >= "
+		if f.kind == filterTimeLe {
+			op = " <= "
+		}
+		l.add(col+op+"$%d", time.UnixMilli(uts))
+	}
+
+	return nil
+}
+
+// Parse filters, authorization and pagination of list request
+func parseListRequest(r *http.Request, s *listSpec) (*listRequest, error) {
+	l := &listRequest{
+		spec:     s,
+		limit:    100,
+		envelope: wantEnvelope(r),
+	}
+
+	if s.where != "" {
+		l.where = append(l.where, s.where)
+	}
+
+	// Filters
+	for _, f := range s.filters {
+		if v := r.FormValue(f.param); v != "" {
+			if err := l.addFilter(f, v); err != nil {
+				return nil, fmt.Errorf("Invalid %s value", f.param)
+			}
+		}
+	}
+
+	if s.params != nil {
+		if err := s.params(r, l); err != nil {
+			return nil, err
+		}
+	}
+
+	// Authorization
+	if s.domain != "" {
+		u := requestUser(r)
+		switch {
+		case u == nil:
+			l.where = append(l.where, "false")
+		case !u.isAdmin():
+			doms := []int64{}
+			for d := range u.Domains {
+				if u.domainLevel(d) >= readLevel {
+					doms = append(doms, d)
+				}
+			}
+			l.add(s.domain+" = ANY($%d)", doms)
+		}
+	}
+
+	// Sorting
+	v := r.FormValue("sort")
+	if v == "" {
+		v = s.sort
+	}
+	if v != "" {
+		if err := l.parseSort(v); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, k := range s.keys {
+			l.order = append(l.order, orderColumn{column: k})
+		}
+	}
+
+	// Pagination
+	lp := paginateValues(r)
+	if lp[0] != nil {
+		l.limit = *lp[0]
+	}
+	if lp[1] != nil {
+		l.offset = *lp[1]
+	}
+
+	if v := r.FormValue("cursor"); v != "" {
+		c, err := decodeCursor(v)
+		if err != nil || c.Sort != l.sort || len(c.Values) != len(l.order) {
+			return nil, errors.New("Invalid cursor")
+		}
+		l.cursor = c
+		l.offset = 0
+	}
+
+	return l, nil
+}
+
+// Table columns of sqlc model in field order. Column names are JSON tags of fields
+func modelColumns(t reflect.Type) []string {
+	res := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		res = append(res, strings.Split(t.Field(i).Tag.Get("json"), ",")[0])
+	}
+
+	return res
+}
+
+// Index of sqlc model field of table column. Returns -1 if not found
+func modelField(t reflect.Type, column string) int {
+	for i, c := range modelColumns(t) {
+		if c == column {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Scan row into sqlc model. Row must contain table columns in model field order
+func scanModel[T any](row pgx.Row) (T, error) {
+	var s T
+	v := reflect.ValueOf(&s).Elem()
+
+	dst := make([]any, v.NumField())
+	for i := range dst {
+		dst[i] = v.Field(i).Addr().Interface()
+	}
+
+	err := row.Scan(dst...)
+	return s, err
+}
+
+// Cursor pointing to row
+func rowCursor[T any](s T, l *listRequest, prev bool) (string, error) {
+	v := reflect.ValueOf(s)
+	c := listCursor{Sort: l.sort, Prev: prev}
+
+	for _, k := range l.orderColumns() {
+		b, err := json.Marshal(v.Field(modelField(v.Type(), k)).Interface())
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, b)
+	}
+
+	return c.encode(), nil
+}
+
+// SQL ORDER BY expression of list request for sqlc model t. Order is reversed if backward is set
+func (l *listRequest) orderBy(t reflect.Type, backward bool) (string, error) {
+	order := make([]string, 0, len(l.order))
+	for _, o := range l.order {
+		if modelField(t, o.column) < 0 {
+			return "", fmt.Errorf("unknown order column %s", o.column)
+		}
+
+		dir := "ASC"
+		if o.desc != backward {
+			dir = "DESC"
+		}
+		order = append(order, "t."+o.column+" "+dir)
+	}
+
+	return strings.Join(order, ", "), nil
+}
+
+// SQL WHERE clause of list request filters and authorization
+func (l *listRequest) whereClause() string {
+	if len(l.where) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(l.where, " AND ")
+}
+
+// Check if value is SQL NULL
+func isNullValue(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer {
+		return v.IsNil()
+	}
+
+	if vl, ok := v.Interface().(driver.Valuer); ok {
+		val, err := vl.Value()
+		return err == nil && val == nil
+	}
+
+	return false
+}
+
+// SQL condition of rows after boundary row values in given order.
+// NULLs are last in ascending and first in descending order like in PostgreSQL.
+// Argument placeholders are numbered from n+1
+func keysetCondition(order []orderColumn, vals []reflect.Value, backward bool, n int) (string, []any) {
+	var args []any
+	or := make([]string, 0, len(order))
+	eq := []string{}
+
+	for i, o := range order {
+		col := "t." + o.column
+		null := isNullValue(vals[i])
+		ph := ""
+		if !null {
+			args = append(args, vals[i].Interface())
+			ph = "$" + strconv.Itoa(n+len(args))
+		}
+
+		// Rows after value in column
+		var after string
+		switch desc := o.desc != backward; {
+		case null && desc:
+			after = col + " IS NOT NULL"
+		case null:
+			after = ""
+		case desc:
+			after = col + " < " + ph
+		default:
+			after = "(" + col + " > " + ph + " OR " + col + " IS NULL)"
+		}
+		if after != "" {
+			or = append(or, "("+strings.Join(append(append([]string{}, eq...), after), " AND ")+")")
+		}
+
+		if null {
+			eq = append(eq, col+" IS NULL")
+		} else {
+			eq = append(eq, col+" = "+ph)
+		}
+	}
+
+	if len(or) == 0 {
+		return "false", args
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+// Query page of resource rows in requested order.
+// If envelope is requested, total number of filtered rows is counted in the same snapshot
+func listRows[T any](h *Handler, l *listRequest) ([]T, listPage, error) {
+	page := listPage{
+		Limit:    l.limit,
+		Offset:   l.offset,
+		envelope: l.envelope,
+	}
+
+	if !l.envelope {
+		res, err := queryRows[T](h, h.db, l, &page)
+		return res, page, err
+	}
+
+	tx, err := h.db.BeginTx(h.ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, page, err
+	}
+	defer tx.Rollback(h.ctx)
+
+	err = tx.QueryRow(h.ctx, "SELECT count(*) FROM "+l.spec.table+" t"+l.whereClause(), l.args...).Scan(&page.Total)
+	if err != nil {
+		return nil, page, err
+	}
+
+	res, err := queryRows[T](h, tx, l, &page)
+	if err != nil {
+		return nil, page, err
+	}
+
+	return res, page, tx.Commit(h.ctx)
+}
+
+// Query page of resource rows and set cursors of neighbour pages
+func queryRows[T any](h *Handler, db listQuerier, l *listRequest, page *listPage) ([]T, error) {
+	t := reflect.TypeOf(*new(T))
+	s := l.spec
+
+	where := append([]string{}, l.where...)
+	args := append([]any{}, l.args...)
+
+	// Previous page is queried in reverse order
+	backward := l.cursor != nil && l.cursor.Prev
+	order, err := l.orderBy(t, backward)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keyset condition
+	if l.cursor != nil {
+		vals := make([]reflect.Value, 0, len(l.order))
+		for i, o := range l.order {
+			val := reflect.New(t.Field(modelField(t, o.column)).Type)
+			if err := json.Unmarshal(l.cursor.Values[i], val.Interface()); err != nil {
+				return nil, err
+			}
+			vals = append(vals, val.Elem())
+		}
+
+		if l.sort == "" {
+			// Primary key order can use row comparison
+			ph := make([]string, 0, len(vals))
+			for _, v := range vals {
+				args = append(args, v.Interface())
+				ph = append(ph, "$"+strconv.Itoa(len(args)))
+			}
+
+			cmp := ">"
+			if backward {
+				cmp = "<"
+			}
+			where = append(where, "(t."+strings.Join(s.keys, ", t.")+") "+cmp+" ("+strings.Join(ph, ", ")+")")
+		} else {
+			cond, a := keysetCondition(l.order, vals, backward, len(args))
+			args = append(args, a...)
+			where = append(where, cond)
+		}
+	}
+
+	query := "SELECT t." + strings.Join(modelColumns(t), ", t.") + " FROM " + s.table + " t"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// One extra row tells if there are more rows
+	args = append(args, l.limit+1, l.offset)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(args)-1, len(args))
+
+	rows, err := db.Query(h.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []T{}
+	for rows.Next() {
+		a, err := scanModel[T](rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	more := len(res) > int(l.limit)
+	if more {
+		res = res[:l.limit]
+	}
+
+	// Restore order of previous page
+	if backward {
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+	}
+
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	// Neighbour pages
+	if more || backward {
+		if page.Next, err = rowCursor(res[len(res)-1], l, false); err != nil {
+			return nil, err
+		}
+	}
+	if (backward && more) || (!backward && (l.cursor != nil || l.offset > 0)) {
+		if page.Prev, err = rowCursor(res[0], l, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// URL of list page with cursor. Empty cursor means first page
+func pageURL(r *http.Request, cursor string) string {
+	u := *r.URL
+	q := u.Query()
+	q.Del("offset")
+	q.Del("cursor")
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.RequestURI()
+}
+
+// Set cursors of neighbour pages in response headers and as RFC 5988 Link header
+func setPageHeaders(w http.ResponseWriter, r *http.Request, page listPage) {
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(r, ""))}
+	if page.Next != "" {
+		w.Header().Set("X-Next-Cursor", page.Next)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, page.Next)))
+	}
+	if page.Prev != "" {
+		w.Header().Set("X-Prev-Cursor", page.Prev)
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, page.Prev)))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// Respond with list page. Cursors of neighbour pages are set in response headers
+// and as RFC 5988 Link header. Payload is wrapped in ListResponse if envelope is requested
+// or returned as CSV if CSV is requested
+func respondList(w http.ResponseWriter, r *http.Request, page listPage, payload interface{}) {
+	setPageHeaders(w, r, page)
+
+	if wantCSV(r) {
+		respondCSV(w, r, http.StatusOK, payload)
+		return
+	}
+
+	if !page.envelope {
+		RespondJSON(w, r, http.StatusOK, payload)
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, ListResponse{
+		Items:      payload,
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.Next,
+		PrevCursor: page.Prev,
+	})
+}
+
+// Count rows of list request filters and authorization
+func countRows(h *Handler, l *listRequest) (int64, error) {
+	var res int64
+	err := h.db.QueryRow(h.ctx, "SELECT count(*) FROM "+l.spec.table+" t"+l.whereClause(), l.args...).Scan(&res)
+
+	return res, err
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	name := "core"
+	var null *string
+
+	tests := []struct {
+		name     string
+		order    []orderColumn
+		vals     []any
+		backward bool
+		n        int
+		want     string
+		args     []any
+	}{
+		{
+			name:  "ascending",
+			order: []orderColumn{{"dev_id", false}},
+			vals:  []any{int64(7)},
+			want:  "(((t.dev_id > $1 OR t.dev_id IS NULL)))",
+			args:  []any{int64(7)},
+		},
+		{
+			name:  "descending",
+			order: []orderColumn{{"dev_id", true}},
+			vals:  []any{int64(7)},
+			want:  "((t.dev_id < $1))",
+			args:  []any{int64(7)},
+		},
+		{
+			name:     "backward ascending",
+			order:    []orderColumn{{"dev_id", false}},
+			vals:     []any{int64(7)},
+			backward: true,
+			want:     "((t.dev_id < $1))",
+			args:     []any{int64(7)},
+		},
+		{
+			name:     "backward descending",
+			order:    []orderColumn{{"dev_id", true}},
+			vals:     []any{int64(7)},
+			backward: true,
+			want:     "(((t.dev_id > $1 OR t.dev_id IS NULL)))",
+			args:     []any{int64(7)},
+		},
+		{
+			name:  "placeholder offset",
+			order: []orderColumn{{"dev_id", false}},
+			vals:  []any{int64(7)},
+			n:     2,
+			want:  "(((t.dev_id > $3 OR t.dev_id IS NULL)))",
+			args:  []any{int64(7)},
+		},
+		{
+			name:  "sort column and primary key",
+			order: []orderColumn{{"host_name", false}, {"dev_id", false}},
+			vals:  []any{&name, int64(7)},
+			want: "(((t.host_name > $1 OR t.host_name IS NULL)) OR " +
+				"(t.host_name = $1 AND (t.dev_id > $2 OR t.dev_id IS NULL)))",
+			args: []any{&name, int64(7)},
+		},
+		{
+			name:  "mixed directions",
+			order: []orderColumn{{"host_name", true}, {"dev_id", false}},
+			vals:  []any{&name, int64(7)},
+			want:  "((t.host_name < $1) OR (t.host_name = $1 AND (t.dev_id > $2 OR t.dev_id IS NULL)))",
+			args:  []any{&name, int64(7)},
+		},
+		{
+			name:  "null ascending is last",
+			order: []orderColumn{{"notes", false}, {"dev_id", false}},
+			vals:  []any{null, int64(7)},
+			want:  "((t.notes IS NULL AND (t.dev_id > $1 OR t.dev_id IS NULL)))",
+			args:  []any{int64(7)},
+		},
+		{
+			name:  "null descending is first",
+			order: []orderColumn{{"notes", true}, {"dev_id", true}},
+			vals:  []any{null, int64(7)},
+			want:  "((t.notes IS NOT NULL) OR (t.notes IS NULL AND t.dev_id < $1))",
+			args:  []any{int64(7)},
+		},
+		{
+			name:  "no rows after last null",
+			order: []orderColumn{{"notes", false}},
+			vals:  []any{null},
+			want:  "false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vals := make([]reflect.Value, 0, len(tt.vals))
+			for _, v := range tt.vals {
+				vals = append(vals, reflect.ValueOf(v))
+			}
+
+			got, args := keysetCondition(tt.order, vals, tt.backward, tt.n)
+			if got != tt.want {
+				t.Errorf("condition = %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestParseListSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		sort  string
+		order []orderColumn
+		err   bool
+	}{
+		{"default sort", "", "-audit_id", []orderColumn{{"audit_id", true}}, false},
+		{"requested sort", "sort=username", "username", []orderColumn{{"username", false}, {"audit_id", false}}, false},
+		{"unsortable field", "sort=before", "", nil, true},
+		{"unknown field", "sort=nope", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/audit?"+tt.query, nil)
+			l, err := parseListRequest(r, &auditList)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseListRequest(%q) error = %v, want error %v", tt.query, err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if l.sort != tt.sort {
+				t.Errorf("sort = %s, want %s", l.sort, tt.sort)
+			}
+			if !reflect.DeepEqual(l.order, tt.order) {
+				t.Errorf("order = %v, want %v", l.order, tt.order)
+			}
+		})
+	}
+}
@@ -54,17 +54,30 @@ func (r *ospfNbr) updateParams() godevmandb.UpdateOspfNbrParams {
 
 // Count OspfNbrs
 // @Summary Count ospf_nbrs
-// @Description Count number of ospf_nbrs
+// @Description Count number of ospf_nbrs matching filters
 // @Tags devices
 // @ID count-ospf_nbrs
+// @Param condition_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param nbr_ip_f query string false "ip or containing net in CIDR notation"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/ospf_nbrs/count [GET]
 func (h *Handler) CountOspfNbrs(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountOspfNbrs(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &ospfNbrsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -73,6 +86,22 @@ func (h *Handler) CountOspfNbrs(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of ospf nbrs
+var ospfNbrsList = listSpec{
+	table:  "ospf_nbrs",
+	keys:   []string{"nbr_id"},
+	domain: domainOfDevChild,
+	model:  ospfNbr{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"condition_f", "condition", filterILikeNull},
+		{"nbr_ip_f", "nbr_ip", filterInet},
+	},
+}
+
 // List ospf_nbrs
 // @Summary List ospf_nbrs
 // @Description List ospf_nbrs info
@@ -81,54 +110,33 @@ func (h *Handler) CountOspfNbrs(w http.ResponseWriter, r *http.Request) {
 // @Param condition_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
 // @Param nbr_ip_f query string false "ip or containing net in CIDR notation"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} ospfNbr
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/ospf_nbrs [GET]
 func (h *Handler) GetOspfNbrs(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetOspfNbrsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-
-	if v := r.FormValue("condition_f"); v != "" {
-		p.ConditionF = &v
-	}
-
-	p.NbrIpF = strToPgInet(nil)
-	if v := r.FormValue("nbr_ip_f"); v != "" {
-		p.NbrIpF = strToPgInet(&v)
+	// Query DB
+	l, err := parseListRequest(r, &ospfNbrsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetOspfNbrs(h.ctx, p)
+	res, page, err := listRows[godevmandb.OspfNbr](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -141,7 +149,7 @@ func (h *Handler) GetOspfNbrs(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get OspfNbr
@@ -150,7 +158,10 @@ func (h *Handler) GetOspfNbrs(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-ospf_nbr
 // @Param nbr_id path string true "nbr_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} ospfNbr
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid nbr_id"
 // @Failure 404 {object} StatusResponse "OspfNbr not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -225,10 +236,13 @@ func (h *Handler) CreateOspfNbr(w http.ResponseWriter, r *http.Request) {
 // @ID update-ospf_nbr
 // @Param nbr_id path string true "nbr_id"
 // @Param Body body ospfNbr true "JSON object of ospfNbr.<br />Ignored fields:<ul><li>nbr_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} ospfNbr
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/ospf_nbrs/{nbr_id} [PUT]
 func (h *Handler) UpdateOspfNbr(w http.ResponseWriter, r *http.Request) {
@@ -264,16 +278,39 @@ func (h *Handler) UpdateOspfNbr(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch OspfNbr
+// @Summary Patch ospf_nbr
+// @Description Partially update ospf_nbr with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-ospf_nbr
+// @Param nbr_id path string true "nbr_id"
+// @Param Body body ospfNbr true "JSON merge patch of ospfNbr.<br />Ignored fields:<ul><li>nbr_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} ospfNbr
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/ospf_nbrs/{nbr_id} [PATCH]
+func (h *Handler) PatchOspfNbr(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetOspfNbr, h.UpdateOspfNbr)
+}
+
 // Delete OspfNbr
 // @Summary Delete ospf_nbr
 // @Description Delete ospf_nbr
 // @Tags devices
 // @ID delete-ospf_nbr
 // @Param nbr_id path string true "nbr_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid nbr_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/ospf_nbrs/{nbr_id} [DELETE]
 func (h *Handler) DeleteOspfNbr(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aretaja/godevmandb"
+	"github.com/go-chi/chi/v5"
+)
+
+// Mapping of client interface onto OTN interface (interfaces.otn_if_id)
+type otnMapping struct {
+	IfID    int64  `json:"if_id"`
+	DevID   int64  `json:"dev_id"`
+	Descr   string `json:"descr"`
+	OtnIfID int64  `json:"otn_if_id"`
+}
+
+// Input parameters of OTN mapping create and update
+type otnMappingParams struct {
+	IfID    int64 `json:"if_id"`
+	OtnIfID int64 `json:"otn_if_id"`
+}
+
+// Set OTN interface of client interface. Zero otnIfID removes mapping.
+// Returns false if client interface does not exist
+func (h *Handler) setOtnIf(id, otnIfID int64) (otnMapping, bool, error) {
+	var out otnMapping
+	var otn *int64
+	if otnIfID != 0 {
+		otn = &otnIfID
+	}
+
+	var res *int64
+	err := h.db.QueryRow(h.ctx,
+		`UPDATE interfaces SET otn_if_id = $2 WHERE if_id = $1
+		 RETURNING if_id, dev_id, descr, otn_if_id`, id, otn).Scan(&out.IfID, &out.DevID, &out.Descr, &res)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return out, false, nil
+		}
+		return out, false, err
+	}
+	if res != nil {
+		out.OtnIfID = *res
+	}
+
+	return out, true, nil
+}
+
+// Count OtnMappings
+// @Summary Count otn mappings
+// @Description Count number of client interfaces mapped onto OTN interfaces matching filters
+// @Tags interfaces
+// @ID count-otn_mappings
+// @Param dev_id_f query string false "SQL '=' operator value"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param otn_if_id_f query string false "SQL '=' operator value"
+// @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn/count [GET]
+func (h *Handler) CountOtnMappings(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &otnMappingsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
+}
+
+// List query specification of OTN mappings
+var otnMappingsList = listSpec{
+	table:  "interfaces",
+	keys:   []string{"if_id"},
+	domain: domainOfDevChild,
+	where:  "t.otn_if_id IS NOT NULL",
+	model:  otnMapping{},
+	filters: []listFilter{
+		{"dev_id_f", "dev_id", filterEq},
+		{"descr_f", "descr", filterILike},
+		{"otn_if_id_f", "otn_if_id", filterEq},
+	},
+}
+
+// List OtnMappings
+// @Summary List otn mappings
+// @Description List client interfaces mapped onto OTN interfaces
+// @Tags interfaces
+// @ID list-otn_mappings
+// @Param dev_id_f query string false "SQL '=' operator value"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param otn_if_id_f query string false "SQL '=' operator value"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Success 200 {array} otnMapping
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn [GET]
+func (h *Handler) GetOtnMappings(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &otnMappingsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[otnMapping](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get OtnMapping
+// @Summary Get otn mapping
+// @Description Get OTN mapping of client interface
+// @Tags interfaces
+// @ID get-otn_mapping
+// @Param if_id path string true "if_id of client interface"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} otnMapping
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid if_id"
+// @Failure 404 {object} StatusResponse "Mapping not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn/{if_id} [GET]
+func (h *Handler) GetOtnMapping(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "if_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface ID")
+		return
+	}
+
+	out := otnMapping{}
+	err = h.db.QueryRow(h.ctx,
+		`SELECT if_id, dev_id, descr, otn_if_id FROM interfaces
+		  WHERE if_id = $1 AND otn_if_id IS NOT NULL`, id).Scan(&out.IfID, &out.DevID, &out.Descr, &out.OtnIfID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Mapping not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Create OtnMapping
+// @Summary Create otn mapping
+// @Description Map client interface onto OTN interface. Change is audited as update of client interface
+// @Tags interfaces
+// @ID create-otn_mapping
+// @Param Body body otnMappingParams true "JSON object of otnMappingParams"
+// @Success 201 {object} otnMapping
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 404 {object} StatusResponse "Interface not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn [POST]
+func (h *Handler) CreateOtnMapping(w http.ResponseWriter, r *http.Request) {
+	var p otnMappingParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil || p.OtnIfID == 0 || p.IfID == p.OtnIfID {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	res, ok, err := h.setOtnIf(p.IfID, p.OtnIfID)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		RespondError(w, r, http.StatusNotFound, "Interface not found")
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, res)
+}
+
+// Update OtnMapping
+// @Summary Update otn mapping
+// @Description Change OTN interface of client interface
+// @Tags interfaces
+// @ID update-otn_mapping
+// @Param if_id path string true "if_id of client interface"
+// @Param Body body otnMappingParams true "JSON object of otnMappingParams.<br />Ignored fields:<ul><li>if_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} otnMapping
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Interface not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn/{if_id} [PUT]
+func (h *Handler) UpdateOtnMapping(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "if_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface ID")
+		return
+	}
+
+	var p otnMappingParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil || p.OtnIfID == 0 || id == p.OtnIfID {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	res, ok, err := h.setOtnIf(id, p.OtnIfID)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		RespondError(w, r, http.StatusNotFound, "Interface not found")
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, res)
+}
+
+// Patch OtnMapping
+// @Summary Patch otn mapping
+// @Description Partially update otn mapping with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags interfaces
+// @ID patch-otn_mapping
+// @Param if_id path string true "if_id of client interface"
+// @Param Body body otnMappingParams true "JSON merge patch of otnMappingParams.<br />Ignored fields:<ul><li>if_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} otnMapping
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Interface not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn/{if_id} [PATCH]
+func (h *Handler) PatchOtnMapping(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetOtnMapping, h.UpdateOtnMapping)
+}
+
+// Delete OtnMapping
+// @Summary Delete otn mapping
+// @Description Remove OTN mapping of client interface
+// @Tags interfaces
+// @ID delete-otn_mapping
+// @Param if_id path string true "if_id of client interface"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid if_id"
+// @Failure 404 {object} StatusResponse "Mapping not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/otn/{if_id} [DELETE]
+func (h *Handler) DeleteOtnMapping(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "if_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface ID")
+		return
+	}
+
+	res, err := h.db.Exec(h.ctx, `UPDATE interfaces SET otn_if_id = NULL WHERE if_id = $1 AND otn_if_id IS NOT NULL`, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if res.RowsAffected() == 0 {
+		RespondError(w, r, http.StatusNotFound, "Mapping not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Relations
+// List OTN Interface Clients
+// @Summary List otn interface clients
+// @Description List client interfaces mapped onto OTN interface
+// @Tags interfaces
+// @ID list-interface-otn_clients
+// @Param if_id path string true "if_id of OTN interface"
+// @Success 200 {array} iface
+// @Failure 400 {object} StatusResponse "Invalid if_id"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/{if_id}/otn_clients [GET]
+func (h *Handler) GetInterfaceOtnClients(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "if_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid interface ID")
+		return
+	}
+
+	cols := modelColumns(reflect.TypeOf(godevmandb.Interface{}))
+	rows, err := h.db.Query(h.ctx, "SELECT "+strings.Join(cols, ", ")+" FROM interfaces WHERE otn_if_id = $1 ORDER BY if_id", id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	res := []godevmandb.Interface{}
+	for rows.Next() {
+		s, err := scanModel[godevmandb.Interface](rows)
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Authorization. Client interfaces may be in other device domains
+	res, err = authzFilter(h, r, domOfInterface, res, func(s godevmandb.Interface) int64 { return s.IfID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := []iface{}
+	for _, s := range res {
+		a := iface{}
+		a.getValues(s)
+		out = append(out, a)
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Media type of JSON merge patch (RFC 7396)
+const mergePatchType = "application/merge-patch+json"
+
+// Apply JSON merge patch (RFC 7396) to target document
+func mergePatchValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatchValue(t[k], v)
+		}
+	}
+
+	return t
+}
+
+// Decode JSON document. Numbers are kept as json.Number to preserve precision
+func decodeJSONValue(b []byte) (any, error) {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Handle PATCH request with JSON merge patch (RFC 7396) payload.
+// Current representation of resource is fetched using get handler,
+// patch is applied to it and result is passed to update handler.
+// Responses of get handler other than 200 are returned as is.
+// Request runs in transaction of WriteTx and row is locked by Audit middleware before read,
+// so concurrent update can't be overwritten
+func (h *Handler) mergePatch(w http.ResponseWriter, r *http.Request, get, update http.HandlerFunc) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != mergePatchType && mt != "application/json") {
+			RespondError(w, r, http.StatusUnsupportedMediaType, "Unsupported media type")
+			return
+		}
+	}
+
+	if !h.inTx() {
+		RespondError(w, r, http.StatusInternalServerError, "PATCH requires transaction")
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+
+	patch, err := decodeJSONValue(b)
+	if _, ok := patch.(map[string]any); err != nil || !ok {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Current representation
+	gr := r.Clone(r.Context())
+	gr.Method = http.MethodGet
+	gr.Body = http.NoBody
+	gr.ContentLength = 0
+	gr.Header.Del("Content-Type")
+
+	rec := httptest.NewRecorder()
+	get(rec, gr)
+	if rec.Code != http.StatusOK {
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+		return
+	}
+
+	cur, err := decodeJSONValue(rec.Body.Bytes())
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res, err := json.Marshal(mergePatchValue(cur, patch))
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Update with patched representation
+	ur := r.Clone(r.Context())
+	ur.Body = io.NopCloser(bytes.NewReader(res))
+	ur.ContentLength = int64(len(res))
+	ur.Header.Set("Content-Type", "application/json")
+
+	update(w, ur)
+}
@@ -11,17 +11,29 @@ import (
 
 // Count RlNbrs
 // @Summary Count rl_nbrs
-// @Description Count number of radio link neighbors
+// @Description Count number of radio link neighbors matching filters
 // @Tags devices
 // @ID count-rl_nbrs
+// @Param nbr_sysname_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/rl_nbrs/count [GET]
 func (h *Handler) CountRlNbrs(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountRlNbrs(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &rlNbrsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +42,21 @@ func (h *Handler) CountRlNbrs(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of rl nbrs
+var rlNbrsList = listSpec{
+	table:  "rl_nbrs",
+	keys:   []string{"nbr_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.RlNbr{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"nbr_sysname_f", "nbr_sysname", filterILike},
+	},
+}
+
 // List rl_nbrs
 // @Summary List rl_nbrs
 // @Description List radio link neighbors info
@@ -37,54 +64,39 @@ func (h *Handler) CountRlNbrs(w http.ResponseWriter, r *http.Request) {
 // @ID list-rl_nbrs
 // @Param nbr_sysname_f query string false "url encoded SQL 'ILIKE' operator pattern"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.RlNbr
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/rl_nbrs [GET]
 func (h *Handler) GetRlNbrs(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetRlNbrsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("nbr_sysname_f"); v != "" {
-		p.NbrSysnameF = v
+	// Query DB
+	l, err := parseListRequest(r, &rlNbrsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetRlNbrs(h.ctx, p)
+	res, page, err := listRows[godevmandb.RlNbr](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get RlNbr
@@ -93,7 +105,10 @@ func (h *Handler) GetRlNbrs(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-rl_nbr
 // @Param nbr_id path string true "nbr_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.RlNbr
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid nbr_id"
 // @Failure 404 {object} StatusResponse "Neighbor not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -159,10 +174,13 @@ func (h *Handler) CreateRlNbr(w http.ResponseWriter, r *http.Request) {
 // @ID update-rl_nbr
 // @Param nbr_id path string true "nbr_id"
 // @Param Body body godevmandb.UpdateRlNbrParams true "JSON object of godevmandb.UpdateRlNbrParams.<br />Ignored fields:<ul><li>nbr_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.RlNbr
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/rl_nbrs/{nbr_id} [PUT]
 func (h *Handler) UpdateRlNbr(w http.ResponseWriter, r *http.Request) {
@@ -193,16 +211,39 @@ func (h *Handler) UpdateRlNbr(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch RlNbr
+// @Summary Patch rl_nbr
+// @Description Partially update rl_nbr with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-rl_nbr
+// @Param nbr_id path string true "nbr_id"
+// @Param Body body godevmandb.UpdateRlNbrParams true "JSON merge patch of godevmandb.UpdateRlNbrParams.<br />Ignored fields:<ul><li>nbr_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.RlNbr
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failde DB transaction"
+// @Router /devices/rl_nbrs/{nbr_id} [PATCH]
+func (h *Handler) PatchRlNbr(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetRlNbr, h.UpdateRlNbr)
+}
+
 // Delete RlNbr
 // @Summary Delete rl_nbr
 // @Description Delete radio link neighbor
 // @Tags devices
 // @ID delete-rl_nbr
 // @Param nbr_id path string true "nbr_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid nbr_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/rl_nbrs/{nbr_id} [DELETE]
 func (h *Handler) DeleteRlNbr(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aretaja/godevmandb"
+)
+
+// Media type of GeoJSON response
+const geoJSONType = "application/geo+json"
+
+// Mean radius of Earth in kilometers
+const earthRadiusKm = 6371.0
+
+// GeoJSON point geometry. Coordinates are longitude and latitude
+type geoPoint struct {
+	Type        string     `json:"type" example:"Point"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// Properties of site feature
+type siteProperties struct {
+	godevmandb.Site
+	DeviceCount int64 `json:"device_count"`
+}
+
+// GeoJSON feature of site. Geometry is null if site has no coordinates
+type siteFeature struct {
+	Type       string         `json:"type" example:"Feature"`
+	ID         int64          `json:"id"`
+	Geometry   *geoPoint      `json:"geometry"`
+	Properties siteProperties `json:"properties"`
+}
+
+// GeoJSON feature collection of sites
+type SiteFeatureCollection struct {
+	Type     string        `json:"type" example:"FeatureCollection"`
+	Features []siteFeature `json:"features"`
+}
+
+// Check if GeoJSON response is requested by "format" query parameter or by Accept header
+func wantGeoJSON(r *http.Request) bool {
+	if v := r.FormValue("format"); v != "" {
+		return v == "geojson"
+	}
+
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err == nil && mt == geoJSONType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse comma separated list of n floats
+func parseFloats(v string, n int) ([]float64, error) {
+	parts := strings.Split(v, ",")
+	if len(parts) != n {
+		return nil, errors.New("invalid number of values")
+	}
+
+	res := make([]float64, 0, n)
+	for _, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+
+	return res, nil
+}
+
+// Parse bbox query parameter "min_lon,min_lat,max_lon,max_lat". Returns nil if parameter is not set
+func parseBbox(r *http.Request) ([]float64, error) {
+	v := r.FormValue("bbox")
+	if v == "" {
+		return nil, nil
+	}
+
+	b, err := parseFloats(v, 4)
+	if err != nil || b[0] > b[2] || b[1] > b[3] {
+		return nil, errors.New("Invalid bbox value")
+	}
+
+	return b, nil
+}
+
+// SQL condition of site with alias a being in bbox. Expects bbox values as arguments 1 to 4
+func bboxCondition(a string) string {
+	return a + ".longitude BETWEEN $%[1]d::float8 AND $%[3]d::float8 AND " +
+		a + ".latitude BETWEEN $%[2]d::float8 AND $%[4]d::float8"
+}
+
+// Parse location filters of sites list.
+// bbox is "min_lon,min_lat,max_lon,max_lat" and near is "lat,lon" with radius_km
+func siteGeoFilters(r *http.Request, l *listRequest) error {
+	b, err := parseBbox(r)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		l.addN(bboxCondition("t"), b[0], b[1], b[2], b[3])
+	}
+
+	near, radius := r.FormValue("near"), r.FormValue("radius_km")
+	if near == "" && radius == "" {
+		return nil
+	}
+
+	// Latitude and longitude
+	p, err := parseFloats(near, 2)
+	if err != nil || p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+		return errors.New("Invalid near value")
+	}
+	rad, err := strconv.ParseFloat(radius, 64)
+	if err != nil || rad < 0 {
+		return errors.New("Invalid radius_km value")
+	}
+
+	// Haversine distance
+	l.addN(`$%[4]d::float8 * 2 * asin(least(1, sqrt(power(sin(radians(t.latitude - $%[2]d::float8) / 2), 2) + `+
+		`cos(radians($%[2]d::float8)) * cos(radians(t.latitude)) * power(sin(radians(t.longitude - $%[1]d::float8) / 2), 2)))) <= $%[3]d::float8`,
+		p[1], p[0], rad, earthRadiusKm)
+
+	return nil
+}
+
+// Device domains readable by user. all is true if user can read all domains
+func readDomains(r *http.Request) (doms []int64, all bool) {
+	u := requestUser(r)
+	if u != nil && u.isAdmin() {
+		return nil, true
+	}
+
+	doms = []int64{}
+	if u != nil {
+		for d := range u.Domains {
+			if u.domainLevel(d) >= readLevel {
+				doms = append(doms, d)
+			}
+		}
+	}
+
+	return doms, false
+}
+
+// Numbers of devices on sites. Only devices readable by user are counted
+func (h *Handler) siteDeviceCounts(r *http.Request, ids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64)
+
+	query := `SELECT site_id, count(*) FROM devices WHERE site_id = ANY($1)`
+	args := []any{ids}
+	if doms, all := readDomains(r); !all {
+		query += ` AND dom_id = ANY($2)`
+		args = append(args, doms)
+	}
+
+	rows, err := h.db.Query(h.ctx, query+` GROUP BY site_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, n int64
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		res[id] = n
+	}
+
+	return res, rows.Err()
+}
+
+// Coordinate as float64 with shortest decimal representation of float32 value
+func geoCoord(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', -1, 32), 64)
+	return f
+}
+
+// Respond with GeoJSON feature collection of sites
+func (h *Handler) respondSitesGeoJSON(w http.ResponseWriter, r *http.Request, page listPage, sites []godevmandb.Site) {
+	ids := make([]int64, 0, len(sites))
+	for _, s := range sites {
+		ids = append(ids, s.SiteID)
+	}
+
+	counts, err := h.siteDeviceCounts(r, ids)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := SiteFeatureCollection{Type: "FeatureCollection", Features: make([]siteFeature, 0, len(sites))}
+	for _, s := range sites {
+		f := siteFeature{
+			Type:       "Feature",
+			ID:         s.SiteID,
+			Properties: siteProperties{Site: s, DeviceCount: counts[s.SiteID]},
+		}
+		if s.Latitude != nil && s.Longitude != nil {
+			f.Geometry = &geoPoint{Type: "Point", Coordinates: [2]float64{geoCoord(*s.Longitude), geoCoord(*s.Latitude)}}
+		}
+		out.Features = append(out.Features, f)
+	}
+
+	res, err := json.Marshal(out)
+	if err != nil {
+		log.Print(err.Error())
+		RespondError(w, r, http.StatusInternalServerError, "JSON marshal failed")
+		return
+	}
+
+	setPageHeaders(w, r, page)
+	w.Header().Set("Content-Type", geoJSONType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(res)
+}
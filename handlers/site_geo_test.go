@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseFloats(t *testing.T) {
+	tests := []struct {
+		name string
+		v    string
+		n    int
+		want []float64
+		err  bool
+	}{
+		{"two values", "59.43,24.75", 2, []float64{59.43, 24.75}, false},
+		{"spaces", " 59.43 , -24.75 ", 2, []float64{59.43, -24.75}, false},
+		{"four values", "1,2,3,4", 4, []float64{1, 2, 3, 4}, false},
+		{"too few values", "1,2,3", 4, nil, true},
+		{"too many values", "1,2,3", 2, nil, true},
+		{"empty", "", 2, nil, true},
+		{"not number", "1,x", 2, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFloats(tt.v, tt.n)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseFloats(%q, %d) error = %v, want error %v", tt.v, tt.n, err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFloats(%q, %d) = %v, want %v", tt.v, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteGeoFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		err   bool
+	}{
+		{"no filters", "", nil, false},
+		{"bbox", "bbox=24.5,59.3,25.0,59.6", []any{24.5, 59.3, 25.0, 59.6}, false},
+		{"bbox min above max", "bbox=25.0,59.3,24.5,59.6", nil, true},
+		{"bbox too few values", "bbox=24.5,59.3,25.0", nil, true},
+		{"near is lat,lon", "near=59.43,24.75&radius_km=10", []any{24.75, 59.43, 10.0, earthRadiusKm}, false},
+		{"near longitude beyond 90", "near=59.43,120.5&radius_km=10", []any{120.5, 59.43, 10.0, earthRadiusKm}, false},
+		{"near latitude beyond 90", "near=120.5,59.43&radius_km=10", nil, true},
+		{"near longitude beyond 180", "near=59.43,181&radius_km=10", nil, true},
+		{"near without radius", "near=59.43,24.75", nil, true},
+		{"radius without near", "radius_km=10", nil, true},
+		{"negative radius", "near=59.43,24.75&radius_km=-1", nil, true},
+		{"bbox and near", "bbox=24.5,59.3,25.0,59.6&near=59.43,24.75&radius_km=10",
+			[]any{24.5, 59.3, 25.0, 59.6, 24.75, 59.43, 10.0, earthRadiusKm}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/sites?"+tt.query, nil)
+			l := &listRequest{}
+			err := siteGeoFilters(r, l)
+			if (err != nil) != tt.err {
+				t.Fatalf("siteGeoFilters(%q) error = %v, want error %v", tt.query, err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(l.args, tt.args) {
+				t.Errorf("siteGeoFilters(%q) args = %v, want %v", tt.query, l.args, tt.args)
+			}
+		})
+	}
+}
@@ -11,17 +11,38 @@ import (
 
 // Count Sites
 // @Summary Count sites
-// @Description Count number of sites
+// @Description Count number of sites matching filters
 // @Tags sites
 // @ID count-sites
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param uident_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param area_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param addr_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param ext_name_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param ext_id_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param bbox query string false "sites in bounding box 'min_lon,min_lat,max_lon,max_lat'"
+// @Param near query string false "sites within radius_km from point 'lat,lon'"
+// @Param radius_km query number false "radius in kilometers of near filter"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /sites/count [GET]
 func (h *Handler) CountSites(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountSites(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &sitesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,9 +51,30 @@ func (h *Handler) CountSites(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of sites
+var sitesList = listSpec{
+	table: "sites",
+	keys:  []string{"site_id"},
+	model: godevmandb.Site{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"uident_f", "uident", filterILikeNull},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"area_f", "area", filterILikeNull},
+		{"addr_f", "addr", filterILikeNull},
+		{"notes_f", "notes", filterILikeNull},
+		{"ext_name_f", "ext_name", filterILikeNull},
+		{"ext_id_f", "ext_id", filterLikeNull},
+	},
+	params: siteGeoFilters,
+}
+
 // List sites
 // @Summary List sites
-// @Description List site info
+// @Description List site info. With format=geojson response is SiteFeatureCollection
 // @Tags sites
 // @ID list-sites
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
@@ -42,79 +84,48 @@ func (h *Handler) CountSites(w http.ResponseWriter, r *http.Request) {
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param ext_name_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param ext_id_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param bbox query string false "sites in bounding box 'min_lon,min_lat,max_lon,max_lat'"
+// @Param near query string false "sites within radius_km from point 'lat,lon'"
+// @Param radius_km query number false "radius in kilometers of near filter"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row, 'geojson' for GeoJSON FeatureCollection with device counts. Alternatively set Accept header \"text/csv\" or \"application/geo+json\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Site
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /sites [GET]
 func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetSitesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("uident_f"); v != "" {
-		p.UidentF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("area_f"); v != "" {
-		p.AreaF = &v
-	}
-
-	if v := r.FormValue("addr_f"); v != "" {
-		p.AddrF = &v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
-	}
-
-	if v := r.FormValue("extid_f"); v != "" {
-		p.ExtIDF = &v
-	}
-
-	if v := r.FormValue("extname_f"); v != "" {
-		p.ExtNameF = &v
+	// Query DB
+	l, err := parseListRequest(r, &sitesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetSites(h.ctx, p)
+	res, page, err := listRows[godevmandb.Site](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	if wantGeoJSON(r) {
+		h.respondSitesGeoJSON(w, r, page, res)
+		return
+	}
+
+	respondList(w, r, page, res)
 }
 
 // Get Site
@@ -123,7 +134,10 @@ func (h *Handler) GetSites(w http.ResponseWriter, r *http.Request) {
 // @Tags sites
 // @ID get-site
 // @Param site_id path string true "site_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Site
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid site_id"
 // @Failure 404 {object} StatusResponse "Site not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -189,10 +203,13 @@ func (h *Handler) CreateSite(w http.ResponseWriter, r *http.Request) {
 // @ID update-site
 // @Param site_id path string true "site_id"
 // @Param Body body godevmandb.UpdateSiteParams true "JSON object of godevmandb.UpdateSiteParams.<br />Ignored fields:<ul><li>site_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Site
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /sites/{site_id} [PUT]
 func (h *Handler) UpdateSite(w http.ResponseWriter, r *http.Request) {
@@ -224,16 +241,39 @@ func (h *Handler) UpdateSite(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Site
+// @Summary Patch site
+// @Description Partially update site with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags sites
+// @ID patch-site
+// @Param site_id path string true "site_id"
+// @Param Body body godevmandb.UpdateSiteParams true "JSON merge patch of godevmandb.UpdateSiteParams.<br />Ignored fields:<ul><li>site_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Site
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /sites/{site_id} [PATCH]
+func (h *Handler) PatchSite(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetSite, h.UpdateSite)
+}
+
 // Delete Site
 // @Summary Delete site
 // @Description Delete site
 // @Tags sites
 // @ID delete-site
 // @Param site_id path string true "site_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid site_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /sites/{site_id} [DELETE]
 func (h *Handler) DeleteSite(w http.ResponseWriter, r *http.Request) {
@@ -253,6 +293,38 @@ func (h *Handler) DeleteSite(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of sites
+var sitesBulk = bulkSpec{
+	scope: ScopeShared,
+	audit: AuditSite,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateSiteParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateSite(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.SiteID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateSiteParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.SiteID = id
+
+		return q.UpdateSite(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteSite(h.ctx, id)
+	},
+}
+
 // Foreign key
 // Get Site Country
 // @Summary Get site country
@@ -310,6 +382,13 @@ func (h *Handler) GetSiteDevices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfDevice, res, func(s godevmandb.Device) int64 { return s.DevID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []device{}
 	for _, s := range res {
 		r := device{}
@@ -25,8 +25,8 @@ type snmpCredential struct {
 	Variant    int32                     `json:"variant"`
 }
 
-// Import values from corresponding godevmandb struct
-func (r *snmpCredential) getValues(s godevmandb.SnmpCredential) error {
+// Import values from corresponding godevmandb struct. Secrets are masked
+func (r *snmpCredential) getValues(s godevmandb.SnmpCredential) {
 	r.SnmpCredID = s.SnmpCredID
 	r.Variant = s.Variant
 	r.Label = s.Label
@@ -38,8 +38,33 @@ func (r *snmpCredential) getValues(s godevmandb.SnmpCredential) error {
 	r.CreatedOn = s.CreatedOn
 
 	if s.AuthPass != nil {
-		enc := *s.AuthPass
-		val, err := godevmandb.DecryptStrAes(enc, salt)
+		val := maskSecret(*s.AuthPass)
+		r.AuthPass = &val
+	}
+
+	if s.PrivPass != nil {
+		val := maskSecret(*s.PrivPass)
+		r.PrivPass = &val
+	}
+}
+
+// Encrypted secrets of credential. Used as entity tag source of masked values
+func snmpSecrets(s godevmandb.SnmpCredential) []string {
+	res := make([]string, 2)
+	if s.AuthPass != nil {
+		res[0] = *s.AuthPass
+	}
+	if s.PrivPass != nil {
+		res[1] = *s.PrivPass
+	}
+
+	return res
+}
+
+// Import decrypted secrets from corresponding godevmandb struct
+func (r *snmpCredential) revealValues(s godevmandb.SnmpCredential) error {
+	if s.AuthPass != nil {
+		val, err := decryptSecret(*s.AuthPass)
 		if err != nil {
 			return err
 		}
@@ -48,8 +73,7 @@ func (r *snmpCredential) getValues(s godevmandb.SnmpCredential) error {
 	}
 
 	if s.PrivPass != nil {
-		enc := *s.PrivPass
-		val, err := godevmandb.DecryptStrAes(enc, salt)
+		val, err := decryptSecret(*s.PrivPass)
 		if err != nil {
 			return err
 		}
@@ -72,21 +96,21 @@ func (r *snmpCredential) createParams() (godevmandb.CreateSnmpCredentialParams,
 	s.PrivProto = snmpPrivProtoToNullSnmpPrivProto(r.PrivProto)
 
 	if r.AuthPass != nil {
-		val, err := godevmandb.EncryptStrAes(*r.AuthPass, salt)
+		val, err := encryptSecret(*r.AuthPass)
 		if err != nil {
 			return s, err
 		}
 
-		*s.AuthPass = val
+		s.AuthPass = &val
 	}
 
 	if r.PrivPass != nil {
-		val, err := godevmandb.EncryptStrAes(*r.PrivPass, salt)
+		val, err := encryptSecret(*r.PrivPass)
 		if err != nil {
 			return s, err
 		}
 
-		*s.PrivPass = val
+		s.PrivPass = &val
 	}
 
 	return s, nil
@@ -104,21 +128,21 @@ func (r *snmpCredential) updateParams() (godevmandb.UpdateSnmpCredentialParams,
 	s.PrivProto = snmpPrivProtoToNullSnmpPrivProto(r.PrivProto)
 
 	if r.AuthPass != nil {
-		val, err := godevmandb.EncryptStrAes(*r.AuthPass, salt)
+		val, err := encryptSecret(*r.AuthPass)
 		if err != nil {
 			return s, err
 		}
 
-		*s.AuthPass = val
+		s.AuthPass = &val
 	}
 
 	if r.PrivPass != nil {
-		val, err := godevmandb.EncryptStrAes(*r.PrivPass, salt)
+		val, err := encryptSecret(*r.PrivPass)
 		if err != nil {
 			return s, err
 		}
 
-		*s.PrivPass = val
+		s.PrivPass = &val
 	}
 
 	return s, nil
@@ -126,17 +150,30 @@ func (r *snmpCredential) updateParams() (godevmandb.UpdateSnmpCredentialParams,
 
 // Count SnmpCredentials
 // @Summary Count snmp_credentials
-// @Description Count number of snmp credentials
+// @Description Count number of snmp credentials matching filters
 // @Tags config
 // @ID count-snmp_credentials
+// @Param label_f query string false "url encoded SQL 'LIKE' operator pattern"
+// @Param variant_f query string false "SQL '=' operator value"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/snmp_credentials/count [GET]
 func (h *Handler) CountSnmpCredentials(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountSnmpCredentials(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &snmpCredentialsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -145,60 +182,57 @@ func (h *Handler) CountSnmpCredentials(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of snmp credentials
+var snmpCredentialsList = listSpec{
+	table:   "snmp_credentials",
+	keys:    []string{"snmp_cred_id"},
+	model:   snmpCredential{},
+	columns: map[string]string{"snmp_snmp_cred_id": "snmp_cred_id"},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"label_f", "label", filterILike},
+		{"variant_f", "variant", filterEq},
+	},
+}
+
 // List SnmpCredentials
 // @Summary List snmp_credentials
-// @Description List snmp credentials info
+// @Description List snmp credentials info. Secrets are masked
 // @Tags config
 // @ID list-snmp_credentials
 // @Param label_f query string false "url encoded SQL 'LIKE' operator pattern"
 // @Param variant_f query string false "SQL '=' operator value"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} snmpCredential
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/snmp_credentials [GET]
 func (h *Handler) GetSnmpCredentials(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetSnmpCredentialsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("label_f"); v != "" {
-		p.LabelF = v
-	}
-
-	if v := r.FormValue("variant_f"); v != "" {
-		p.VariantF = v
+	// Query DB
+	l, err := parseListRequest(r, &snmpCredentialsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetSnmpCredentials(h.ctx, p)
+	res, page, err := listRows[godevmandb.SnmpCredential](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -211,16 +245,19 @@ func (h *Handler) GetSnmpCredentials(w http.ResponseWriter, r *http.Request) {
 		out = append(out, r)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get SnmpCredential
 // @Summary Get snmp_credential
-// @Description Get snmp credential info
+// @Description Get snmp credential info. Secrets are masked
 // @Tags config
 // @ID get-snmp_credential
 // @Param snmp_cred_id path string true "snmp_cred_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} snmpCredential
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid snmp_cred_id"
 // @Failure 404 {object} StatusResponse "Credential not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -246,6 +283,59 @@ func (h *Handler) GetSnmpCredential(w http.ResponseWriter, r *http.Request) {
 
 	out := snmpCredential{}
 	out.getValues(res)
+	setETagSource(w, snmpSecrets(res)...)
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Reveal SnmpCredential
+// @Summary Reveal snmp_credential
+// @Description Get snmp credential info with decrypted secrets. Access is recorded in audit log
+// @Tags config
+// @ID reveal-snmp_credential
+// @Param snmp_cred_id path string true "snmp_cred_id"
+// @Success 200 {object} snmpCredential
+// @Failure 400 {object} StatusResponse "Invalid snmp_cred_id"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Credential not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/snmp_credentials/{snmp_cred_id}/reveal [GET]
+func (h *Handler) RevealSnmpCredential(w http.ResponseWriter, r *http.Request) {
+	// Revealed secret can't be taken back if transaction is rolled back
+	if h.inTx() {
+		RespondError(w, r, http.StatusBadRequest, "Reveal is not allowed in batch")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "snmp_cred_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid credential ID")
+		return
+	}
+
+	q := godevmandb.New(h.db)
+	res, err := q.GetSnmpCredential(h.ctx, id)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Credential not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	out := snmpCredential{}
+	out.getValues(res)
+	if err := out.revealValues(res); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.audit(r, "reveal", "snmp_credentials", id); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	RespondJSON(w, r, http.StatusOK, out)
 }
@@ -293,15 +383,18 @@ func (h *Handler) CreateSnmpCredential(w http.ResponseWriter, r *http.Request) {
 
 // Update SnmpCredential
 // @Summary Update snmp_credential
-// @Description Update snmp credential
+// @Description Update snmp credential. Masked secret value keeps existing secret
 // @Tags config
 // @ID update-snmp_credential
 // @Param snmp_cred_id path string true "snmp_cred_id"
 // @Param Body body snmpCredential true "JSON object of credential.<br />Ignored fields:<ul><li>snmp_cred_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} snmpCredential
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
-// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 404 {object} StatusResponse "Credential not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/snmp_credentials/{snmp_cred_id} [PUT]
 func (h *Handler) UpdateSnmpCredential(w http.ResponseWriter, r *http.Request) {
@@ -319,6 +412,31 @@ func (h *Handler) UpdateSnmpCredential(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
+	q := godevmandb.New(h.db)
+
+	// Keep existing secrets if masked values are sent back
+	var cur godevmandb.SnmpCredential
+	authMasked := pIn.AuthPass != nil && *pIn.AuthPass == secretMask
+	privMasked := pIn.PrivPass != nil && *pIn.PrivPass == secretMask
+	if authMasked || privMasked {
+		cur, err = q.GetSnmpCredential(h.ctx, id)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				RespondError(w, r, http.StatusNotFound, "Credential not found")
+			} else {
+				RespondError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		if authMasked {
+			pIn.AuthPass = nil
+		}
+		if privMasked {
+			pIn.PrivPass = nil
+		}
+	}
+
 	// Update parameters for new db record
 	p, err := pIn.updateParams()
 	if err != nil {
@@ -327,31 +445,63 @@ func (h *Handler) UpdateSnmpCredential(w http.ResponseWriter, r *http.Request) {
 	}
 
 	p.SnmpCredID = id
+	if authMasked {
+		p.AuthPass = cur.AuthPass
+	}
+	if privMasked {
+		p.PrivPass = cur.PrivPass
+	}
 
-	q := godevmandb.New(h.db)
 	res, err := q.UpdateSnmpCredential(h.ctx, p)
-
 	if err != nil {
-		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Credential not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
 	out := snmpCredential{}
 	out.getValues(res)
+	setETagSource(w, snmpSecrets(res)...)
 
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch SnmpCredential
+// @Summary Patch snmp_credential
+// @Description Partially update snmp_credential with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags config
+// @ID patch-snmp_credential
+// @Param snmp_cred_id path string true "snmp_cred_id"
+// @Param Body body snmpCredential true "JSON merge patch of credential.<br />Ignored fields:<ul><li>snmp_cred_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} snmpCredential
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/snmp_credentials/{snmp_cred_id} [PATCH]
+func (h *Handler) PatchSnmpCredential(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetSnmpCredential, h.UpdateSnmpCredential)
+}
+
 // Delete SnmpCredential
 // @Summary Delete snmp_credential
 // @Description Delete snmp credential
 // @Tags config
 // @ID delete-snmp_credential
 // @Param snmp_cred_id path string true "snmp_cred_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid snmp_cred_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/snmp_credentials/{snmp_cred_id} [DELETE]
 func (h *Handler) DeleteSnmpCredential(w http.ResponseWriter, r *http.Request) {
@@ -398,6 +548,13 @@ func (h *Handler) GetSnmpCredentialsMainDevices(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfDevice, res, func(s godevmandb.Device) int64 { return s.DevID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []device{}
 	for _, s := range res {
 		a := device{}
@@ -435,6 +592,13 @@ func (h *Handler) GetSnmpCredentialsRoDevices(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Authorization
+	res, err = authzFilter(h, r, domOfDevice, res, func(s godevmandb.Device) int64 { return s.DevID })
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	out := []device{}
 	for _, s := range res {
 		a := device{}
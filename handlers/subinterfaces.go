@@ -82,17 +82,37 @@ func (r *subinterface) updateParams() godevmandb.UpdateSubinterfaceParams {
 
 // Count Subinterfaces
 // @Summary Count subinterfaces
-// @Description Count number of subinterfaces
+// @Description Count number of subinterfaces matching filters
 // @Tags interfaces
 // @ID count-subinterfaces
+// @Param ifindex_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param alias_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param oper_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param adm_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param speed_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param type_enum_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param mac_f query string false "SQL '=' operator value (MAC address)"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/subinterfaces/count [GET]
 func (h *Handler) CountSubinterfaces(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountSubinterfaces(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &subinterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -101,6 +121,29 @@ func (h *Handler) CountSubinterfaces(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of subinterfaces
+var subinterfacesList = listSpec{
+	table:  "subinterfaces",
+	keys:   []string{"sif_id"},
+	domain: domainOfInterface,
+	model:  subinterface{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"ifindex_f", "ifindex", filterLikeNull},
+		{"descr_f", "descr", filterILikeEmpty},
+		{"alias_f", "alias", filterILikeNull},
+		{"oper_f", "oper", filterLikeNull},
+		{"adm_f", "adm", filterLikeNull},
+		{"speed_f", "speed", filterLikeNull},
+		{"type_enum_f", "type_enum", filterLikeNull},
+		{"mac_f", "mac", filterMacaddr},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List subinterfaces
 // @Summary List subinterfaces
 // @Description List subinterfaces info
@@ -116,81 +159,33 @@ func (h *Handler) CountSubinterfaces(w http.ResponseWriter, r *http.Request) {
 // @Param notes_f query string false "url encoded SQL 'LIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param mac_f query string false "SQL '=' operator value (MAC address)"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} subinterface
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/subinterfaces [GET]
 func (h *Handler) GetSubinterfaces(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetSubinterfacesParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("ifindex_f"); v != "" {
-		p.IfindexF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("alias_f"); v != "" {
-		p.AliasF = &v
-	}
-
-	if v := r.FormValue("oper_f"); v != "" {
-		p.OperF = &v
-	}
-
-	if v := r.FormValue("adm_f"); v != "" {
-		p.AdmF = &v
-	}
-
-	if v := r.FormValue("speed_f"); v != "" {
-		p.SpeedF = &v
-	}
-
-	if v := r.FormValue("type_enum_f"); v != "" {
-		p.TypeEnumF = &v
-	}
-
-	p.MacF = strToPgMacaddr(nil)
-	if v := r.FormValue("mac_f"); v != "" {
-		p.MacF = strToPgMacaddr(&v)
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &subinterfacesList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetSubinterfaces(h.ctx, p)
+	res, page, err := listRows[godevmandb.Subinterface](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -203,7 +198,7 @@ func (h *Handler) GetSubinterfaces(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get Subinterface
@@ -212,7 +207,10 @@ func (h *Handler) GetSubinterfaces(w http.ResponseWriter, r *http.Request) {
 // @Tags interfaces
 // @ID get-subinterface
 // @Param sif_id path string true "sif_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} subinterface
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid sif_id"
 // @Failure 404 {object} StatusResponse "Subinterface not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -287,10 +285,13 @@ func (h *Handler) CreateSubinterface(w http.ResponseWriter, r *http.Request) {
 // @ID update-subinterface
 // @Param sif_id path string true "sif_id"
 // @Param Body body subinterface true "JSON object of subinterface.<br />Ignored fields:<ul><li>sif_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} subinterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/subinterfaces/{sif_id} [PUT]
 func (h *Handler) UpdateSubinterface(w http.ResponseWriter, r *http.Request) {
@@ -326,16 +327,39 @@ func (h *Handler) UpdateSubinterface(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch Subinterface
+// @Summary Patch subinterface
+// @Description Partially update subinterface with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags interfaces
+// @ID patch-subinterface
+// @Param sif_id path string true "sif_id"
+// @Param Body body subinterface true "JSON merge patch of subinterface.<br />Ignored fields:<ul><li>sif_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} subinterface
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /interfaces/subinterfaces/{sif_id} [PATCH]
+func (h *Handler) PatchSubinterface(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetSubinterface, h.UpdateSubinterface)
+}
+
 // Delete Subinterface
 // @Summary Delete subinterface
 // @Description Delete subinterface
 // @Tags interfaces
 // @ID delete-subinterface
 // @Param sif_id path string true "sif_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid sif_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /interfaces/subinterfaces/{sif_id} [DELETE]
 func (h *Handler) DeleteSubinterface(w http.ResponseWriter, r *http.Request) {
@@ -355,6 +379,67 @@ func (h *Handler) DeleteSubinterface(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of subinterfaces
+var subinterfacesBulk = bulkSpec{
+	scope: ScopeSubinterface,
+	audit: AuditSubinterface,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var pIn subinterface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateSubinterface(h.ctx, pIn.createParams())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		out := subinterface{}
+		out.getValues(res)
+
+		return res.SifID, out, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var pIn subinterface
+		if err := bulkDecode(b, &pIn); err != nil {
+			return nil, err
+		}
+
+		p := pIn.updateParams()
+		p.SifID = id
+
+		res, err := q.UpdateSubinterface(h.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		out := subinterface{}
+		out.getValues(res)
+
+		return out, nil
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteSubinterface(h.ctx, id)
+	},
+}
+
+// Bulk Subinterfaces
+// @Summary Bulk change subinterfaces
+// @Description Create, update and delete subinterfaces in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags interfaces
+// @ID bulk-subinterfaces
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create and update items is JSON object of subinterface"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /interfaces/subinterfaces/bulk [POST]
+func (h *Handler) BulkSubinterfaces(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, subinterfacesBulk)
+}
+
 // Foreign key
 // Get Subinterface Interface
 // @Summary Get subinterface interface
@@ -0,0 +1,563 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Maximum depth of topology request
+const topologyMaxDepth = 5
+
+// Maximum number of nodes in topology graph
+const topologyMaxNodes = 2000
+
+// Error of topology graph exceeding topologyMaxNodes
+var errTopologyTooLarge = fmt.Errorf("Topology has more than %d nodes", topologyMaxNodes)
+
+// Kinds of topology links between devices
+const (
+	linkRl         = "rl"
+	linkOspf       = "ospf"
+	linkXconnect   = "xconnect"
+	linkConnection = "connection"
+)
+
+// Queries of device links. Rows are link kind, owner device ID, peer device ID or NULL,
+// name of unresolved peer, link label and link identity shared by both ends of link. Links are owned by devices in $1 or point to them.
+// Owned links and links pointing to devices from other devices are queried separately,
+// so both can use indexes (see sql/schema.sql)
+var topologyLinkQueries = []string{
+	// RL neighbours by system name
+	`SELECT kind, owner, peer, ext, label, link FROM (
+	   SELECT 'rl' AS kind, n.dev_id AS owner, d.dev_id AS peer, n.nbr_sysname AS ext, '' AS label, '' AS link, n.nbr_id
+	     FROM rl_nbrs n
+	     LEFT JOIN devices d ON d.dev_id <> n.dev_id
+	      AND (lower(d.sys_name) = lower(n.nbr_sysname) OR lower(d.host_name) = lower(n.nbr_sysname))
+	    WHERE n.dev_id = ANY($1)
+	   UNION ALL
+	   SELECT 'rl', n.dev_id, d.dev_id, n.nbr_sysname, '', '', n.nbr_id
+	     FROM devices d
+	     JOIN rl_nbrs n ON (lower(n.nbr_sysname) = lower(d.sys_name) OR lower(n.nbr_sysname) = lower(d.host_name))
+	      AND n.dev_id <> d.dev_id
+	    WHERE d.dev_id = ANY($1) AND n.dev_id <> ALL($1)
+	 ) l
+	 ORDER BY nbr_id, peer`,
+	// OSPF neighbours by IP address of device or its IP interface
+	`SELECT kind, owner, peer, ext, label, link FROM (
+	   SELECT 'ospf' AS kind, n.dev_id AS owner, p.dev_id AS peer, host(n.nbr_ip) AS ext,
+	          coalesce(n.condition, '') AS label, '' AS link, n.nbr_id
+	     FROM ospf_nbrs n
+	     LEFT JOIN (SELECT dev_id, host(ip_addr) AS ip FROM ip_interfaces
+	                UNION ALL SELECT dev_id, host(ip4_addr) FROM devices WHERE ip4_addr IS NOT NULL
+	                UNION ALL SELECT dev_id, host(ip6_addr) FROM devices WHERE ip6_addr IS NOT NULL) p
+	       ON p.ip = host(n.nbr_ip) AND p.dev_id <> n.dev_id
+	    WHERE n.dev_id = ANY($1)
+	   UNION ALL
+	   SELECT 'ospf', n.dev_id, p.dev_id, host(n.nbr_ip), coalesce(n.condition, ''), '', n.nbr_id
+	     FROM (SELECT dev_id, host(ip_addr) AS ip FROM ip_interfaces WHERE dev_id = ANY($1)
+	           UNION SELECT dev_id, host(ip4_addr) FROM devices WHERE dev_id = ANY($1) AND ip4_addr IS NOT NULL
+	           UNION SELECT dev_id, host(ip6_addr) FROM devices WHERE dev_id = ANY($1) AND ip6_addr IS NOT NULL) p
+	     JOIN ospf_nbrs n ON host(n.nbr_ip) = p.ip AND n.dev_id <> p.dev_id
+	    WHERE n.dev_id <> ALL($1)
+	 ) l
+	 ORDER BY nbr_id, peer`,
+	// Xconnects by peer device or peer IP address
+	`SELECT kind, owner, peer, ext, label, link FROM (
+	   SELECT 'xconnect' AS kind, x.dev_id AS owner, x.peer_dev_id AS peer, coalesce(host(x.peer_ip), '') AS ext,
+	          'vc ' || x.vc_id AS label, coalesce(x.vc_id::text, '') AS link, x.xc_id
+	     FROM xconnects x
+	    WHERE x.dev_id = ANY($1)
+	   UNION ALL
+	   SELECT 'xconnect', x.dev_id, x.peer_dev_id, coalesce(host(x.peer_ip), ''), 'vc ' || x.vc_id,
+	          coalesce(x.vc_id::text, ''), x.xc_id
+	     FROM xconnects x
+	    WHERE x.peer_dev_id = ANY($1) AND x.dev_id <> ALL($1)
+	 ) l
+	 ORDER BY xc_id`,
+}
+
+// Topology graph node
+type topologyNode struct {
+	ID    string `json:"id"`
+	Type  string `json:"type" enums:"device,connection,external"`
+	Label string `json:"label"`
+	DevID int64  `json:"dev_id,omitempty"`
+	ConID int64  `json:"con_id,omitempty"`
+}
+
+// Topology graph edge
+type topologyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type" enums:"rl,ospf,xconnect,connection"`
+	Label  string `json:"label,omitempty"`
+	// Identity of link shared by both ends. Parallel links of same kind differ by it
+	link string
+}
+
+// Topology graph
+type Topology struct {
+	Nodes []topologyNode `json:"nodes"`
+	Edges []topologyEdge `json:"edges"`
+}
+
+// Link found from database
+type topologyLink struct {
+	kind  string
+	owner int64
+	peer  *int64
+	ext   string
+	label string
+	link  string
+}
+
+// Topology graph builder
+type topologyBuilder struct {
+	h     *Handler
+	u     *authUser
+	graph Topology
+	nodes map[string]bool
+	edges map[string]bool
+	// Host names of readable devices. Devices not in map are not readable
+	devices map[int64]string
+}
+
+// Add node if missing
+func (b *topologyBuilder) node(n topologyNode) string {
+	if !b.nodes[n.ID] {
+		b.nodes[n.ID] = true
+		b.graph.Nodes = append(b.graph.Nodes, n)
+	}
+
+	return n.ID
+}
+
+// Add device node
+func (b *topologyBuilder) device(id int64) string {
+	return b.node(topologyNode{ID: "dev:" + strconv.FormatInt(id, 10), Type: "device", Label: b.devices[id], DevID: id})
+}
+
+// Add edge if missing. Link found from both ends is added once even if labels
+// of its ends differ. Parallel links with different identity are added separately
+func (b *topologyBuilder) edge(e topologyEdge) {
+	s, t := e.Source, e.Target
+	if s > t {
+		s, t = t, s
+	}
+
+	k := e.Type + "|" + s + "|" + t + "|" + e.link
+	if !b.edges[k] {
+		b.edges[k] = true
+		b.graph.Edges = append(b.graph.Edges, e)
+	}
+}
+
+// Load host names of readable devices
+func (b *topologyBuilder) loadDevices(ids []int64) error {
+	rows, err := b.h.db.Query(b.h.ctx, `SELECT dev_id, dom_id, host_name FROM devices WHERE dev_id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, dom int64
+		var name string
+		if err := rows.Scan(&id, &dom, &name); err != nil {
+			return err
+		}
+		if b.u.domainLevel(dom) >= readLevel {
+			b.devices[id] = name
+		}
+	}
+
+	return rows.Err()
+}
+
+// Links of devices
+func (b *topologyBuilder) links(ids []int64) ([]topologyLink, error) {
+	res := []topologyLink{}
+	for _, q := range topologyLinkQueries {
+		rows, err := b.h.db.Query(b.h.ctx, q, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var l topologyLink
+			if err := rows.Scan(&l.kind, &l.owner, &l.peer, &l.ext, &l.label, &l.link); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			res = append(res, l)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Add connections of devices. Returns devices sharing connections
+func (b *topologyBuilder) connections(ids []int64) ([]int64, error) {
+	rows, err := b.h.db.Query(b.h.ctx,
+		`SELECT i.dev_id, c.con_id, coalesce(c.hint, ''), i.descr
+		   FROM interfaces i
+		   JOIN connections c ON c.con_id = i.con_id
+		  WHERE i.con_id IN (SELECT con_id FROM interfaces WHERE dev_id = ANY($1))
+		  ORDER BY c.con_id, i.if_id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type ifCon struct {
+		devID, conID int64
+		hint, descr  string
+	}
+	all := []ifCon{}
+	devs := []int64{}
+	for rows.Next() {
+		var c ifCon
+		if err := rows.Scan(&c.devID, &c.conID, &c.hint, &c.descr); err != nil {
+			return nil, err
+		}
+		all = append(all, c)
+		devs = append(devs, c.devID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := b.loadDevices(devs); err != nil {
+		return nil, err
+	}
+
+	res := []int64{}
+	for _, c := range all {
+		if _, ok := b.devices[c.devID]; !ok {
+			continue
+		}
+
+		label := c.hint
+		if label == "" {
+			label = "connection " + strconv.FormatInt(c.conID, 10)
+		}
+		con := b.node(topologyNode{ID: "con:" + strconv.FormatInt(c.conID, 10), Type: "connection", Label: label, ConID: c.conID})
+		b.edge(topologyEdge{Source: b.device(c.devID), Target: con, Type: linkConnection, Label: c.descr, link: c.descr})
+		res = append(res, c.devID)
+	}
+
+	return res, nil
+}
+
+// Build topology graph of seed devices and their neighbours up to depth hops
+func (b *topologyBuilder) build(seeds []int64, depth int) error {
+	if err := b.loadDevices(seeds); err != nil {
+		return err
+	}
+
+	visited := make(map[int64]bool)
+	frontier := []int64{}
+	for _, id := range seeds {
+		if _, ok := b.devices[id]; ok && !visited[id] {
+			visited[id] = true
+			frontier = append(frontier, id)
+			b.device(id)
+		}
+	}
+	if len(b.graph.Nodes) > topologyMaxNodes {
+		return errTopologyTooLarge
+	}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		links, err := b.links(frontier)
+		if err != nil {
+			return err
+		}
+
+		ids := []int64{}
+		for _, l := range links {
+			ids = append(ids, l.owner)
+			if l.peer != nil {
+				ids = append(ids, *l.peer)
+			}
+		}
+		if err := b.loadDevices(ids); err != nil {
+			return err
+		}
+
+		next := []int64{}
+		reach := func(id int64) {
+			if !visited[id] {
+				visited[id] = true
+				next = append(next, id)
+			}
+		}
+
+		for _, l := range links {
+			// Links recorded by devices not readable by user are skipped
+			if _, ok := b.devices[l.owner]; !ok {
+				continue
+			}
+
+			e := topologyEdge{Source: b.device(l.owner), Type: l.kind, Label: l.label, link: l.link}
+			peer := false
+			if l.peer != nil {
+				_, peer = b.devices[*l.peer]
+			}
+			if peer {
+				e.Target = b.device(*l.peer)
+				reach(*l.peer)
+			} else {
+				ext := l.ext
+				if ext == "" {
+					ext = "unknown"
+				}
+				e.Target = b.node(topologyNode{ID: "ext:" + ext, Type: "external", Label: ext})
+			}
+			b.edge(e)
+			reach(l.owner)
+		}
+
+		shared, err := b.connections(frontier)
+		if err != nil {
+			return err
+		}
+		for _, id := range shared {
+			reach(id)
+		}
+
+		// Graph is not expanded further if it gets too large
+		if len(b.graph.Nodes) > topologyMaxNodes {
+			return errTopologyTooLarge
+		}
+
+		frontier = next
+	}
+
+	return nil
+}
+
+// Escape string for DOT quoted ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Escape string for Mermaid quoted text
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
+
+// Graphviz DOT representation of topology
+func (t Topology) dot() string {
+	var sb strings.Builder
+	sb.WriteString("graph topology {\n")
+	shapes := map[string]string{"device": "box", "connection": "hexagon", "external": "ellipse"}
+	for _, n := range t.Nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=%s];\n", dotQuote(n.ID), dotQuote(n.Label), shapes[n.Type])
+	}
+	styles := map[string]string{linkRl: "solid", linkOspf: "bold", linkXconnect: "dashed", linkConnection: "dotted"}
+	for _, e := range t.Edges {
+		fmt.Fprintf(&sb, "  %s -- %s [label=%s, style=%s];\n",
+			dotQuote(e.Source), dotQuote(e.Target), dotQuote(strings.TrimSpace(e.Type+" "+e.Label)), styles[e.Type])
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Mermaid flowchart representation of topology
+func (t Topology) mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+
+	// Mermaid IDs can not contain arbitrary characters
+	ids := make(map[string]string, len(t.Nodes))
+	for i, n := range t.Nodes {
+		id := "n" + strconv.Itoa(i)
+		ids[n.ID] = id
+		switch n.Type {
+		case "device":
+			fmt.Fprintf(&sb, "  %s[%s]\n", id, mermaidQuote(n.Label))
+		case "connection":
+			fmt.Fprintf(&sb, "  %s{{%s}}\n", id, mermaidQuote(n.Label))
+		default:
+			fmt.Fprintf(&sb, "  %s((%s))\n", id, mermaidQuote(n.Label))
+		}
+	}
+	arrows := map[string]string{linkRl: "---", linkOspf: "===", linkXconnect: "-.-", linkConnection: "---"}
+	for _, e := range t.Edges {
+		fmt.Fprintf(&sb, "  %s %s|%s| %s\n",
+			ids[e.Source], arrows[e.Type], mermaidQuote(strings.TrimSpace(e.Type+" "+e.Label)), ids[e.Target])
+	}
+
+	return sb.String()
+}
+
+// Get Topology
+// @Summary Get topology
+// @Description Get network topology graph of device, site or device domain and neighbour devices up to depth hops.
+// @Description Links are built from RL neighbours, OSPF neighbours, xconnects and connections of interfaces.
+// @Description Neighbours not found in database or not readable by user are external nodes.
+// @Description Graph is limited to 2000 nodes.
+// @Description Response is Topology JSON object, Graphviz DOT (text/vnd.graphviz) or Mermaid flowchart (text/vnd.mermaid)
+// @Tags topology
+// @ID get-topology
+// @Param dev_id query int false "device ID"
+// @Param site_id query int false "site ID"
+// @Param dom_id query int false "device domain ID"
+// @Param depth query int false "min: 1; max: 5; default: 1"
+// @Param format query string false "json, dot or mermaid; default: json" Enums(json,dot,mermaid)
+// @Success 200 {object} Topology
+// @Failure 400 {object} StatusResponse "Invalid request or too large topology"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Device, site or device domain not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /topology [GET]
+func (h *Handler) GetTopology(w http.ResponseWriter, r *http.Request) {
+	u := requestUser(r)
+	if u == nil {
+		RespondError(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "dot" && format != "mermaid" {
+		RespondError(w, r, http.StatusBadRequest, "Invalid format value")
+		return
+	}
+
+	depth := 1
+	if v := r.FormValue("depth"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > topologyMaxDepth {
+			RespondError(w, r, http.StatusBadRequest, "Invalid depth value")
+			return
+		}
+		depth = d
+	}
+
+	// Scope of seed devices
+	var param string
+	var id int64
+	for _, p := range []string{"dev_id", "site_id", "dom_id"} {
+		v := r.FormValue(p)
+		if v == "" {
+			continue
+		}
+		if param != "" {
+			RespondError(w, r, http.StatusBadRequest, "Only one of dev_id, site_id and dom_id is allowed")
+			return
+		}
+
+		var err error
+		if id, err = strconv.ParseInt(v, 10, 64); err != nil {
+			RespondError(w, r, http.StatusBadRequest, "Invalid "+p+" value")
+			return
+		}
+		param = p
+	}
+	if param == "" {
+		RespondError(w, r, http.StatusBadRequest, "One of dev_id, site_id and dom_id is required")
+		return
+	}
+
+	switch param {
+	case "dev_id":
+		ok, err := h.authzRow(u, domOfDevice, id, readLevel)
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !ok {
+			RespondError(w, r, http.StatusForbidden, "Access denied")
+			return
+		}
+	case "dom_id":
+		if u.domainLevel(id) < readLevel {
+			RespondError(w, r, http.StatusForbidden, "Access denied")
+			return
+		}
+	}
+
+	rows, err := h.db.Query(h.ctx, `SELECT dev_id FROM devices WHERE `+param+` = $1 ORDER BY dev_id`, id)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	seeds := []int64{}
+	for rows.Next() {
+		var d int64
+		if err := rows.Scan(&d); err != nil {
+			rows.Close()
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		seeds = append(seeds, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(seeds) == 0 {
+		var found bool
+		msg := "Device not found"
+		switch param {
+		case "site_id":
+			msg = "Site not found"
+			err = h.db.QueryRow(h.ctx, `SELECT EXISTS (SELECT 1 FROM sites WHERE site_id = $1)`, id).Scan(&found)
+		case "dom_id":
+			msg = "Device domain not found"
+			err = h.db.QueryRow(h.ctx, `SELECT EXISTS (SELECT 1 FROM device_domains WHERE dom_id = $1)`, id).Scan(&found)
+		}
+		if err != nil {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			RespondError(w, r, http.StatusNotFound, msg)
+			return
+		}
+	}
+
+	b := topologyBuilder{
+		h:       h,
+		u:       u,
+		graph:   Topology{Nodes: []topologyNode{}, Edges: []topologyEdge{}},
+		nodes:   make(map[string]bool),
+		edges:   make(map[string]bool),
+		devices: make(map[int64]string),
+	}
+	if err := b.build(seeds, depth); err != nil {
+		if errors.Is(err, errTopologyTooLarge) {
+			RespondError(w, r, http.StatusBadRequest, err.Error()+". Reduce depth")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	switch format {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(b.graph.dot()))
+	case "mermaid":
+		w.Header().Set("Content-Type", "text/vnd.mermaid; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(b.graph.mermaid()))
+	default:
+		RespondJSON(w, r, http.StatusOK, b.graph)
+	}
+}
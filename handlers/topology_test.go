@@ -0,0 +1,86 @@
+package handlers
+
+import "testing"
+
+func TestDotQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `""`},
+		{"core-router", `"core-router"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+		{`\"`, `"\\\""`},
+		{"line1\nline2", `"line1\nline2"`},
+	}
+
+	for _, tt := range tests {
+		if got := dotQuote(tt.in); got != tt.want {
+			t.Errorf("dotQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMermaidQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", `""`},
+		{"core-router", `"core-router"`},
+		{`say "hi"`, `"say #quot;hi#quot;"`},
+		{"line1\nline2", `"line1 line2"`},
+		{"a[b]{c}", `"a[b]{c}"`},
+	}
+
+	for _, tt := range tests {
+		if got := mermaidQuote(tt.in); got != tt.want {
+			t.Errorf("mermaidQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTopologyEdge(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []topologyEdge
+		want  int
+	}{
+		{"single", []topologyEdge{{Source: "dev:1", Target: "dev:2", Type: linkRl}}, 1},
+		{"both ends", []topologyEdge{
+			{Source: "dev:1", Target: "dev:2", Type: linkRl},
+			{Source: "dev:2", Target: "dev:1", Type: linkRl},
+		}, 1},
+		{"both ends with different labels", []topologyEdge{
+			{Source: "dev:1", Target: "dev:2", Type: linkOspf, Label: "full"},
+			{Source: "dev:2", Target: "dev:1", Type: linkOspf, Label: "2way"},
+		}, 1},
+		{"different types", []topologyEdge{
+			{Source: "dev:1", Target: "dev:2", Type: linkRl},
+			{Source: "dev:1", Target: "dev:2", Type: linkOspf},
+		}, 2},
+		{"different targets", []topologyEdge{
+			{Source: "dev:1", Target: "dev:2", Type: linkRl},
+			{Source: "dev:1", Target: "dev:3", Type: linkRl},
+		}, 2},
+		{"parallel links", []topologyEdge{
+			{Source: "dev:1", Target: "dev:2", Type: linkXconnect, Label: "vc 100", link: "100"},
+			{Source: "dev:1", Target: "dev:2", Type: linkXconnect, Label: "vc 200", link: "200"},
+			{Source: "dev:2", Target: "dev:1", Type: linkXconnect, Label: "vc 100", link: "100"},
+			{Source: "dev:2", Target: "dev:1", Type: linkXconnect, Label: "vc 200", link: "200"},
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := topologyBuilder{edges: make(map[string]bool)}
+			for _, e := range tt.edges {
+				b.edge(e)
+			}
+			if len(b.graph.Edges) != tt.want {
+				t.Errorf("got %d edges, want %d", len(b.graph.Edges), tt.want)
+			}
+		})
+	}
+}
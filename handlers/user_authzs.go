@@ -11,17 +11,31 @@ import (
 
 // Count UserAuthzs
 // @Summary Count user_authzs
-// @Description Count number of user_authzs
+// @Description Count number of user_authzs matching filters
 // @Tags users
 // @ID count-user_authzs
+// @Param username_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param userlevel_le query int false "SQL '<=' operator value"
+// @Param userlevel_ge query int false "SQL '>=' operator value"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/authzs/count [GET]
 func (h *Handler) CountUserAuthzs(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountUserAuthzs(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &userAuthzsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +44,22 @@ func (h *Handler) CountUserAuthzs(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of user authzs
+var userAuthzsList = listSpec{
+	table: "user_authzs",
+	keys:  []string{"username", "dom_id"},
+	model: godevmandb.UserAuthz{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"username_f", "username", filterILike},
+		{"userlevel_le", "userlevel", filterLe},
+		{"userlevel_ge", "userlevel", filterLe},
+	},
+}
+
 // List user_authzs
 // @Summary List user_authzs
 // @Description List user_authzs info
@@ -39,62 +69,39 @@ func (h *Handler) CountUserAuthzs(w http.ResponseWriter, r *http.Request) {
 // @Param userlevel_le query int false "SQL '<=' operator value"
 // @Param userlevel_ge query int false "SQL '>=' operator value"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.UserAuthz
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/authzs [GET]
 func (h *Handler) GetUserAuthzs(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetUserAuthzsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("username_f"); v != "" {
-		p.UsernameF = v
-	}
-
-	if v := r.FormValue("userlevel_ge"); v != "" {
-		p.UserlevelGe = v
-	}
-
-	if v := r.FormValue("userlevel_le"); v != "" {
-		p.UserlevelLe = v
+	// Query DB
+	l, err := parseListRequest(r, &userAuthzsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetUserAuthzs(h.ctx, p)
+	res, page, err := listRows[godevmandb.UserAuthz](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get UserAuthz
@@ -104,7 +111,10 @@ func (h *Handler) GetUserAuthzs(w http.ResponseWriter, r *http.Request) {
 // @ID get-user_authz
 // @Param username path string true "username"
 // @Param dom_id path string true "dom_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.UserAuthz
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid username/dom_id"
 // @Failure 404 {object} StatusResponse "UserAuthz not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -175,10 +185,13 @@ func (h *Handler) CreateUserAuthz(w http.ResponseWriter, r *http.Request) {
 // @Param username path string true "username"
 // @Param dom_id path string true "dom_id"
 // @Param Body body godevmandb.UpdateUserAuthzParams true "JSON object of godevmandb.UpdateUserAuthzParams.<br />Ignored fields:<ul><li>username</li><li>dom_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.UserAuthz
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/authzs/{username}/{dom_id} [PUT]
 func (h *Handler) UpdateUserAuthz(w http.ResponseWriter, r *http.Request) {
@@ -210,6 +223,28 @@ func (h *Handler) UpdateUserAuthz(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch UserAuthz
+// @Summary Patch user_authz
+// @Description Partially update user_authz with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags users
+// @ID patch-user_authz
+// @Param username path string true "username"
+// @Param dom_id path string true "dom_id"
+// @Param Body body godevmandb.UpdateUserAuthzParams true "JSON merge patch of godevmandb.UpdateUserAuthzParams.<br />Ignored fields:<ul><li>username</li><li>dom_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.UserAuthz
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/authzs/{username}/{dom_id} [PATCH]
+func (h *Handler) PatchUserAuthz(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetUserAuthz, h.UpdateUserAuthz)
+}
+
 // Delete UserAuthz
 // @Summary Delete user_authz
 // @Description Delete user_authz
@@ -217,10 +252,12 @@ func (h *Handler) UpdateUserAuthz(w http.ResponseWriter, r *http.Request) {
 // @ID delete-user_authz
 // @Param username path string true "username"
 // @Param dom_id path string true "dom_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid username/dom_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/authzs/{username}/{dom_id} [DELETE]
 func (h *Handler) DeleteUserAuthz(w http.ResponseWriter, r *http.Request) {
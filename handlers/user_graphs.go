@@ -11,17 +11,31 @@ import (
 
 // Count UserGraphs
 // @Summary Count user_graphs
-// @Description Count number of user graphs
+// @Description Count number of user graphs matching filters
 // @Tags users
 // @ID count-user_graphs
+// @Param username_f query string false "url encoded SQL '=' operator pattern"
+// @Param descr_f query string false "url encoded SQL '=' operator pattern"
+// @Param shared_f query bool false "values 'true', 'false'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/graphs/count [GET]
 func (h *Handler) CountUserGraphs(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountUserGraphs(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &userGraphsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +44,22 @@ func (h *Handler) CountUserGraphs(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of user graphs
+var userGraphsList = listSpec{
+	table: "user_graphs",
+	keys:  []string{"graph_id"},
+	model: godevmandb.UserGraph{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"username_f", "username", filterEq},
+		{"descr_f", "descr", filterEq},
+		{"shared_f", "shared", filterBool},
+	},
+}
+
 // List user_graphs
 // @Summary List user_graphs
 // @Description List user graphs info
@@ -39,62 +69,39 @@ func (h *Handler) CountUserGraphs(w http.ResponseWriter, r *http.Request) {
 // @Param descr_f query string false "url encoded SQL '=' operator pattern"
 // @Param shared_f query bool false "values 'true', 'false'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.UserGraph
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/graphs [GET]
 func (h *Handler) GetUserGraphs(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetUserGraphsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("username_f"); v != "" {
-		p.UsernameF = v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("shared_f"); v != "" {
-		p.SharedF = v
+	// Query DB
+	l, err := parseListRequest(r, &userGraphsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetUserGraphs(h.ctx, p)
+	res, page, err := listRows[godevmandb.UserGraph](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get UserGraph
@@ -103,7 +110,10 @@ func (h *Handler) GetUserGraphs(w http.ResponseWriter, r *http.Request) {
 // @Tags users
 // @ID get-user_graph
 // @Param graph_id path string true "graph_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.UserGraph
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid graph_id"
 // @Failure 404 {object} StatusResponse "Graph not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -169,10 +179,13 @@ func (h *Handler) CreateUserGraph(w http.ResponseWriter, r *http.Request) {
 // @ID update-user_graph
 // @Param graph_id path string true "graph_id"
 // @Param Body body godevmandb.UpdateUserGraphParams true "JSON object of godevmandb.UpdateUserGraphParams.<br />Ignored fields:<ul><li>graph_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.UserGraph
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/graphs/{graph_id} [PUT]
 func (h *Handler) UpdateUserGraph(w http.ResponseWriter, r *http.Request) {
@@ -203,16 +216,39 @@ func (h *Handler) UpdateUserGraph(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch UserGraph
+// @Summary Patch user_graph
+// @Description Partially update user_graph with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags users
+// @ID patch-user_graph
+// @Param graph_id path string true "graph_id"
+// @Param Body body godevmandb.UpdateUserGraphParams true "JSON merge patch of godevmandb.UpdateUserGraphParams.<br />Ignored fields:<ul><li>graph_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.UserGraph
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/graphs/{graph_id} [PATCH]
+func (h *Handler) PatchUserGraph(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetUserGraph, h.UpdateUserGraph)
+}
+
 // Delete UserGraph
 // @Summary Delete user_graph
 // @Description Delete user graph
 // @Tags users
 // @ID delete-user_graph
 // @Param graph_id path string true "graph_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid graph_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/graphs/{graph_id} [DELETE]
 func (h *Handler) DeleteUserGraph(w http.ResponseWriter, r *http.Request) {
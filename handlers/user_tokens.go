@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v4"
+)
+
+// API token info
+type userTokenInfo struct {
+	ExpiresOn  *time.Time `json:"expires_on"`
+	LastUsedOn *time.Time `json:"last_used_on"`
+	CreatedOn  time.Time  `json:"created_on"`
+	Descr      string     `json:"descr"`
+	Username   string     `json:"username"`
+	TokenID    int64      `json:"token_id"`
+}
+
+// API token info with token value. Token value is returned only on creation
+type userToken struct {
+	userTokenInfo
+	Token string `json:"token,omitempty"`
+}
+
+// Input parameters of API token create and update
+type userTokenParams struct {
+	ExpiresOn *time.Time `json:"expires_on"`
+	Descr     string     `json:"descr"`
+}
+
+const userTokenColumns = `token_id, username, descr, expires_on, last_used_on, created_on`
+
+// Scan API token row
+func (t *userToken) scan(row pgx.Row) error {
+	return row.Scan(&t.TokenID, &t.Username, &t.Descr, &t.ExpiresOn, &t.LastUsedOn, &t.CreatedOn)
+}
+
+// Generate new random API token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Create API token for user
+func (h *Handler) createToken(username string, p userTokenParams) (userToken, error) {
+	out := userToken{}
+
+	t, err := newToken()
+	if err != nil {
+		return out, err
+	}
+
+	err = out.scan(h.db.QueryRow(h.ctx,
+		`INSERT INTO user_tokens (username, descr, token_hash, expires_on)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+userTokenColumns,
+		username, p.Descr, hashToken(t), p.ExpiresOn))
+	if err != nil {
+		return out, err
+	}
+	out.Token = t
+
+	return out, nil
+}
+
+// Limit expiry of token to expiry of API token used in request,
+// so short-lived token can't be used to get longer-lived one
+func (p *userTokenParams) capExpiry(r *http.Request) {
+	u := requestUser(r)
+	if u == nil || u.ExpiresOn == nil {
+		return
+	}
+
+	if p.ExpiresOn == nil || p.ExpiresOn.After(*u.ExpiresOn) {
+		p.ExpiresOn = u.ExpiresOn
+	}
+}
+
+// Create API token without expiry for user. Returns token value
+func (h *Handler) NewUserToken(username, descr string) (string, error) {
+	res, err := h.createToken(username, userTokenParams{Descr: descr})
+	if err != nil {
+		return "", err
+	}
+
+	return res.Token, nil
+}
+
+// Authorization middleware which allows access only to user in URL and admins
+func (h *Handler) AuthorizeSelfOrAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u := requestUser(r)
+		if u == nil || (u.Username != chi.URLParam(r, "username") && !u.isAdmin()) {
+			RespondError(w, r, http.StatusForbidden, "Access denied")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// List query specification of API tokens of user in URL
+var userTokensList = listSpec{
+	table: "user_tokens",
+	keys:  []string{"token_id"},
+	model: userTokenInfo{},
+	filters: []listFilter{
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILikeEmpty},
+	},
+	params: userTokensOwner,
+}
+
+// Limit API tokens list to user in URL
+func userTokensOwner(r *http.Request, l *listRequest) error {
+	l.add("t.username = $%d", chi.URLParam(r, "username"))
+	return nil
+}
+
+// List User Tokens
+// @Summary List user tokens
+// @Description List user API tokens info
+// @Tags users
+// @ID list-user-tokens
+// @Param username path string true "username"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isempty'"
+// @Param limit query int false "min: 1; max: 1000; default: 100"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
+// @Success 200 {array} userTokenInfo
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens [GET]
+func (h *Handler) GetUserTokens(w http.ResponseWriter, r *http.Request) {
+	// Query DB
+	l, err := parseListRequest(r, &userTokensList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, page, err := listRows[userTokenInfo](h, l)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondList(w, r, page, res)
+}
+
+// Get User Token
+// @Summary Get user token
+// @Description Get user API token info
+// @Tags users
+// @ID get-user-token
+// @Param username path string true "username"
+// @Param token_id path string true "token_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
+// @Success 200 {object} userToken
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
+// @Failure 400 {object} StatusResponse "Invalid token_id"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Token not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens/{token_id} [GET]
+func (h *Handler) GetUserToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "token_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	out := userToken{}
+	err = out.scan(h.db.QueryRow(h.ctx,
+		`SELECT `+userTokenColumns+` FROM user_tokens WHERE token_id = $1 AND username = $2`,
+		id, chi.URLParam(r, "username")))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Token not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Create User Token
+// @Summary Create user token
+// @Description Create user API token. Token value is shown only in this response.
+// @Description Expiry is limited to expiry of API token used in request
+// @Tags users
+// @ID create-user-token
+// @Param username path string true "username"
+// @Param Body body userTokenParams true "JSON object of userTokenParams. expires_on is optional"
+// @Success 201 {object} userToken
+// @Failure 400 {object} StatusResponse "Invalid request payload"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens [POST]
+func (h *Handler) CreateUserToken(w http.ResponseWriter, r *http.Request) {
+	var p userTokenParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	p.capExpiry(r)
+
+	out, err := h.createToken(chi.URLParam(r, "username"), p)
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	RespondJSON(w, r, http.StatusCreated, out)
+}
+
+// Update User Token
+// @Summary Update user token
+// @Description Update user API token description and expiry. Set expires_on to current time to expire token.
+// @Description Expiry is limited to expiry of API token used in request
+// @Tags users
+// @ID update-user-token
+// @Param username path string true "username"
+// @Param token_id path string true "token_id"
+// @Param Body body userTokenParams true "JSON object of userTokenParams. Null expires_on means no expiry"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} userToken
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Token not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens/{token_id} [PUT]
+func (h *Handler) UpdateUserToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "token_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	var p userTokenParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&p); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	defer r.Body.Close()
+	p.capExpiry(r)
+
+	out := userToken{}
+	err = out.scan(h.db.QueryRow(h.ctx,
+		`UPDATE user_tokens SET descr = $3, expires_on = $4
+		  WHERE token_id = $1 AND username = $2
+		 RETURNING `+userTokenColumns,
+		id, chi.URLParam(r, "username"), p.Descr, p.ExpiresOn))
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			RespondError(w, r, http.StatusNotFound, "Token not found")
+		} else {
+			RespondError(w, r, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	RespondJSON(w, r, http.StatusOK, out)
+}
+
+// Patch User Token
+// @Summary Patch user token
+// @Description Partially update user API token with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags users
+// @ID patch-user-token
+// @Param username path string true "username"
+// @Param token_id path string true "token_id"
+// @Param Body body userTokenParams true "JSON merge patch of userTokenParams. Null expires_on means no expiry"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} userToken
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Token not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens/{token_id} [PATCH]
+func (h *Handler) PatchUserToken(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetUserToken, h.UpdateUserToken)
+}
+
+// Delete User Token
+// @Summary Delete user token
+// @Description Revoke user API token
+// @Tags users
+// @ID delete-user-token
+// @Param username path string true "username"
+// @Param token_id path string true "token_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 204
+// @Failure 400 {object} StatusResponse "Invalid token_id"
+// @Failure 403 {object} StatusResponse "Access denied"
+// @Failure 404 {object} StatusResponse "Token not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username}/tokens/{token_id} [DELETE]
+func (h *Handler) DeleteUserToken(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "token_id"), 10, 64)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	res, err := h.db.Exec(h.ctx,
+		`DELETE FROM user_tokens WHERE token_id = $1 AND username = $2`,
+		id, chi.URLParam(r, "username"))
+	if err != nil {
+		RespondError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if res.RowsAffected() == 0 {
+		RespondError(w, r, http.StatusNotFound, "Token not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
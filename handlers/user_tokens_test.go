@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCapExpiry(t *testing.T) {
+	soon := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := soon.Add(24 * time.Hour)
+	earlier := soon.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		caller *time.Time
+		in     *time.Time
+		want   *time.Time
+	}{
+		{"caller without expiry", nil, nil, nil},
+		{"caller without expiry keeps expiry", nil, &later, &later},
+		{"no expiry is capped", &soon, nil, &soon},
+		{"later expiry is capped", &soon, &later, &soon},
+		{"earlier expiry is kept", &soon, &earlier, &earlier},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users/u/tokens", nil)
+			r = r.WithContext(context.WithValue(r.Context(), authCtxKey{}, &authUser{Username: "u", ExpiresOn: tt.caller}))
+
+			p := userTokenParams{ExpiresOn: tt.in}
+			p.capExpiry(r)
+			switch {
+			case (p.ExpiresOn == nil) != (tt.want == nil):
+				t.Errorf("expires_on = %v, want %v", p.ExpiresOn, tt.want)
+			case p.ExpiresOn != nil && !p.ExpiresOn.Equal(*tt.want):
+				t.Errorf("expires_on = %v, want %v", *p.ExpiresOn, *tt.want)
+			}
+		})
+	}
+}
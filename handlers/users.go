@@ -10,17 +10,32 @@ import (
 
 // Count Users
 // @Summary Count users
-// @Description Count number of users
+// @Description Count number of users matching filters
 // @Tags users
 // @ID count-users
+// @Param username_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param userlevel_le query int false "SQL '<=' operator value"
+// @Param userlevel_ge query int false "SQL '>=' operator value"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/count [GET]
 func (h *Handler) CountUsers(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountUsers(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &usersList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -29,6 +44,23 @@ func (h *Handler) CountUsers(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of users
+var usersList = listSpec{
+	table: "users",
+	keys:  []string{"username"},
+	model: godevmandb.User{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"username_f", "username", filterEq},
+		{"userlevel_le", "userlevel", filterLe},
+		{"userlevel_ge", "userlevel", filterLe},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List users
 // @Summary List users
 // @Description List users info
@@ -39,66 +71,39 @@ func (h *Handler) CountUsers(w http.ResponseWriter, r *http.Request) {
 // @Param userlevel_le query int false "SQL '<=' operator value"
 // @Param userlevel_ge query int false "SQL '>=' operator value"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.User
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users [GET]
 func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetUsersParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("username_f"); v != "" {
-		p.UsernameF = v
-	}
-
-	if v := r.FormValue("userlevel_ge"); v != "" {
-		p.UserlevelGe = v
-	}
-
-	if v := r.FormValue("userlevel_le"); v != "" {
-		p.UserlevelLe = v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &usersList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetUsers(h.ctx, p)
+	res, page, err := listRows[godevmandb.User](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get User
@@ -107,7 +112,10 @@ func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
 // @Tags users
 // @ID get-user
 // @Param username path string true "username"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.User
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid username"
 // @Failure 404 {object} StatusResponse "User not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -169,10 +177,13 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 // @ID update-user
 // @Param username path string true "username"
 // @Param Body body godevmandb.UpdateUserParams true "JSON object of godevmandb.UpdateUserParams.<br />Ignored fields:<ul><li>username</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.User
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/{username} [PUT]
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
@@ -199,16 +210,39 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch User
+// @Summary Patch user
+// @Description Partially update user with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags users
+// @ID patch-user
+// @Param username path string true "username"
+// @Param Body body godevmandb.UpdateUserParams true "JSON merge patch of godevmandb.UpdateUserParams.<br />Ignored fields:<ul><li>username</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.User
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /users/{username} [PATCH]
+func (h *Handler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetUser, h.UpdateUser)
+}
+
 // Delete User
 // @Summary Delete user
 // @Description Delete user
 // @Tags users
 // @ID delete-user
 // @Param username path string true "username"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid username"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /users/{username} [DELETE]
 func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
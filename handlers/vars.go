@@ -10,17 +10,31 @@ import (
 
 // Count Vars
 // @Summary Count vars
-// @Description Count number of vars
+// @Description Count number of vars matching filters
 // @Tags config
 // @ID count-vars
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern"
+// @Param content_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/vars/count [GET]
 func (h *Handler) CountVars(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountVars(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &varsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -29,6 +43,22 @@ func (h *Handler) CountVars(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of vars
+var varsList = listSpec{
+	table: "vars",
+	keys:  []string{"descr"},
+	model: godevmandb.Var{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILike},
+		{"content_f", "content", filterILikeNull},
+		{"notes_f", "notes", filterILikeNull},
+	},
+}
+
 // List vars
 // @Summary List vars
 // @Description List vars info
@@ -38,62 +68,39 @@ func (h *Handler) CountVars(w http.ResponseWriter, r *http.Request) {
 // @Param content_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param notes_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Var
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/vars [GET]
 func (h *Handler) GetVars(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetVarsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = v
-	}
-
-	if v := r.FormValue("content_f"); v != "" {
-		p.ContentF = &v
-	}
-
-	if v := r.FormValue("notes_f"); v != "" {
-		p.NotesF = &v
+	// Query DB
+	l, err := parseListRequest(r, &varsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetVars(h.ctx, p)
+	res, page, err := listRows[godevmandb.Var](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get Var
@@ -102,7 +109,10 @@ func (h *Handler) GetVars(w http.ResponseWriter, r *http.Request) {
 // @Tags config
 // @ID get-var
 // @Param descr path string true "descr"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Var
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid descr"
 // @Failure 404 {object} StatusResponse "Var not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -164,10 +174,13 @@ func (h *Handler) CreateVar(w http.ResponseWriter, r *http.Request) {
 // @ID update-var
 // @Param descr path string true "descr"
 // @Param Body body godevmandb.UpdateVarParams true "JSON object of godevmandb.UpdateVarParams.<br />Ignored fields:<ul><li>descr</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Var
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/vars/{descr} [PUT]
 func (h *Handler) UpdateVar(w http.ResponseWriter, r *http.Request) {
@@ -194,16 +207,39 @@ func (h *Handler) UpdateVar(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Var
+// @Summary Patch var
+// @Description Partially update var with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags config
+// @ID patch-var
+// @Param descr path string true "descr"
+// @Param Body body godevmandb.UpdateVarParams true "JSON merge patch of godevmandb.UpdateVarParams.<br />Ignored fields:<ul><li>descr</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Var
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /config/vars/{descr} [PATCH]
+func (h *Handler) PatchVar(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetVar, h.UpdateVar)
+}
+
 // Delete Var
 // @Summary Delete var
 // @Description Delete var
 // @Tags config
 // @ID delete-var
 // @Param descr path string true "descr"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid descr"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /config/vars/{descr} [DELETE]
 func (h *Handler) DeleteVar(w http.ResponseWriter, r *http.Request) {
@@ -11,17 +11,30 @@ import (
 
 // Count Vlans
 // @Summary Count vlans
-// @Description Count number of vlans
+// @Description Count number of vlans matching filters
 // @Tags devices
 // @ID count-vlans
+// @Param vlan_f query string false "url encoded SQL 'LIKE' operator pattern"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/vlans/count [GET]
 func (h *Handler) CountVlans(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountVlans(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &vlansList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -30,6 +43,22 @@ func (h *Handler) CountVlans(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of vlans
+var vlansList = listSpec{
+	table:  "vlans",
+	keys:   []string{"v_id"},
+	domain: domainOfDevChild,
+	model:  godevmandb.Vlan{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"descr_f", "descr", filterILikeNull},
+		{"vlan_f", "vlan", filterLike},
+	},
+}
+
 // List vlans
 // @Summary List vlans
 // @Description List vlans info
@@ -38,58 +67,39 @@ func (h *Handler) CountVlans(w http.ResponseWriter, r *http.Request) {
 // @Param vlan_f query string false "url encoded SQL 'LIKE' operator pattern"
 // @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special values 'isnull', 'isempty'"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} godevmandb.Vlan
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/vlans [GET]
 func (h *Handler) GetVlans(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetVlansParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("vlan_f"); v != "" {
-		p.VlanF = v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
+	// Query DB
+	l, err := parseListRequest(r, &vlansList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetVlans(h.ctx, p)
+	res, page, err := listRows[godevmandb.Vlan](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	RespondJSON(w, r, http.StatusOK, res)
+	respondList(w, r, page, res)
 }
 
 // Get Vlan
@@ -98,7 +108,10 @@ func (h *Handler) GetVlans(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-vlan
 // @Param v_id path string true "v_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} godevmandb.Vlan
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid v_id"
 // @Failure 404 {object} StatusResponse "Vlan not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -164,10 +177,13 @@ func (h *Handler) CreateVlan(w http.ResponseWriter, r *http.Request) {
 // @ID update-vlan
 // @Param v_id path string true "v_id"
 // @Param Body body godevmandb.UpdateVlanParams true "JSON object of godevmandb.UpdateVlanParams.<br />Ignored fields:<ul><li>v_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} godevmandb.Vlan
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/vlans/{v_id} [PUT]
 func (h *Handler) UpdateVlan(w http.ResponseWriter, r *http.Request) {
@@ -198,16 +214,39 @@ func (h *Handler) UpdateVlan(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, res)
 }
 
+// Patch Vlan
+// @Summary Patch vlan
+// @Description Partially update vlan with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-vlan
+// @Param v_id path string true "v_id"
+// @Param Body body godevmandb.UpdateVlanParams true "JSON merge patch of godevmandb.UpdateVlanParams.<br />Ignored fields:<ul><li>v_id</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} godevmandb.Vlan
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failde DB transaction"
+// @Router /devices/vlans/{v_id} [PATCH]
+func (h *Handler) PatchVlan(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetVlan, h.UpdateVlan)
+}
+
 // Delete Vlan
 // @Summary Delete vlan
 // @Description Delete vlan
 // @Tags devices
 // @ID delete-vlan
 // @Param v_id path string true "v_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid v_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failde DB transaction"
 // @Router /devices/vlans/{v_id} [DELETE]
 func (h *Handler) DeleteVlan(w http.ResponseWriter, r *http.Request) {
@@ -227,6 +266,55 @@ func (h *Handler) DeleteVlan(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of vlans
+var vlansBulk = bulkSpec{
+	scope: ScopeVlan,
+	audit: AuditVlan,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var p godevmandb.CreateVlanParams
+		if err := bulkDecode(b, &p); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateVlan(h.ctx, p)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return res.VID, res, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var p godevmandb.UpdateVlanParams
+		if err := bulkDecode(b, &p); err != nil {
+			return nil, err
+		}
+
+		p.VID = id
+
+		return q.UpdateVlan(h.ctx, p)
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteVlan(h.ctx, id)
+	},
+}
+
+// Bulk Vlans
+// @Summary Bulk change vlans
+// @Description Create, update and delete vlans in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags devices
+// @ID bulk-vlans
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create items is JSON object of godevmandb.CreateVlanParams and data of update items is JSON object of godevmandb.UpdateVlanParams"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /devices/vlans/bulk [POST]
+func (h *Handler) BulkVlans(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, vlansBulk)
+}
+
 // Foreign key
 // Get Vlan Device
 // @Summary Get vlan device
@@ -90,17 +90,37 @@ func (r *xconnect) updateParams() godevmandb.UpdateXconnectParams {
 
 // Count Xconnects
 // @Summary Count xconnects
-// @Description Count number of xconnects
+// @Description Count number of xconnects matching filters
 // @Tags devices
 // @ID count-xconnects
+// @Param vc_idx_f query string false "url encoded SQL '=' operator pattern"
+// @Param vc_id_f query string false "url encoded SQL '=' operator pattern"
+// @Param peer_ifalias_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param xname_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param descr_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param op_stat_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param op_stat_in_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param op_stat_out_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
+// @Param peer_ip_f query string false "ip or containing net in CIDR notation"
+// @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
+// @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
+// @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
+// @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {object} CountResponse
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/xconnects/count [GET]
 func (h *Handler) CountXconnects(w http.ResponseWriter, r *http.Request) {
-	q := godevmandb.New(h.db)
-	res, err := q.CountXconnects(h.ctx)
+	// Query DB
+	l, err := parseListRequest(r, &xconnectsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, err := countRows(h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -109,6 +129,29 @@ func (h *Handler) CountXconnects(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, CountResponse{Count: res})
 }
 
+// List query specification of xconnects
+var xconnectsList = listSpec{
+	table:  "xconnects",
+	keys:   []string{"xc_id"},
+	domain: domainOfDevChild,
+	model:  xconnect{},
+	filters: []listFilter{
+		{"updated_ge", "updated_on", filterTimeGe},
+		{"updated_le", "updated_on", filterTimeLe},
+		{"created_ge", "created_on", filterTimeGe},
+		{"created_le", "created_on", filterTimeLe},
+		{"vc_idx_f", "vc_idx", filterEq},
+		{"vc_id_f", "vc_id", filterEq},
+		{"peer_ip_f", "peer_ip", filterInet},
+		{"peer_ifalias_f", "peer_ifalias", filterILikeNull},
+		{"xname_f", "xname", filterILikeNull},
+		{"descr_f", "descr", filterILikeNull},
+		{"op_stat_f", "op_stat", filterILikeNull},
+		{"op_stat_in_f", "op_stat_in", filterILikeNull},
+		{"op_stat_out_f", "op_stat_out", filterILikeNull},
+	},
+}
+
 // List xconnects
 // @Summary List xconnects
 // @Description List xconnects info
@@ -124,81 +167,33 @@ func (h *Handler) CountXconnects(w http.ResponseWriter, r *http.Request) {
 // @Param op_stat_out_f query string false "url encoded SQL 'ILIKE' operator pattern + special value 'isnull', 'isempty'"
 // @Param peer_ip_f query string false "ip or containing net in CIDR notation"
 // @Param limit query int false "min: 1; max: 1000; default: 100"
-// @Param offset query int false "default: 0"
+// @Param offset query int false "default: 0. Ignored if cursor is set"
+// @Param cursor query string false "cursor of next or previous page from X-Next-Cursor or X-Prev-Cursor response header"
+// @Param envelope query bool false "wrap items in ListResponse envelope with total count of filtered rows. Alternatively set Accept header profile=\"envelope\""
+// @Param sort query string false "comma separated list of sort fields. Prefix field with \'-\' for descending order. Any JSON field of response object is allowed"
+// @Param format query string false "'csv' for CSV response with header row. Alternatively set Accept header \"text/csv\""
 // @Param updated_ge query int false "record update time >= (unix timestamp in milliseconds)"
 // @Param updated_le query int false "record update time <= (unix timestamp in milliseconds)"
 // @Param created_ge query int false "record creation time >= (unix timestamp in milliseconds)"
 // @Param created_le query int false "record creation time <= (unix timestamp in milliseconds)"
 // @Success 200 {array} xconnect
+// @Header 200 {string} X-Next-Cursor "cursor of next page"
+// @Header 200 {string} X-Prev-Cursor "cursor of previous page"
+// @Header 200 {string} Link "RFC 5988 links of first, next and previous page"
+// @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/xconnects [GET]
 func (h *Handler) GetXconnects(w http.ResponseWriter, r *http.Request) {
-	// Pagination
-	var p = godevmandb.GetXconnectsParams{
-		LimitQ:  100,
-		OffsetQ: 0,
-	}
-
-	lp := paginateValues(r)
-	if lp[0] != nil {
-		if *lp[0] < 100 {
-			p.LimitQ = *lp[0]
-		}
-	}
-	if lp[1] != nil {
-		p.OffsetQ = *lp[1]
-	}
-
-	// Time filter
-	tf := parseTimeFilter(r)
-	p.UpdatedGe = tf[0]
-	p.UpdatedLe = tf[1]
-	p.CreatedGe = tf[2]
-	p.CreatedLe = tf[3]
-
-	// Filters
-	if v := r.FormValue("vc_idx_f"); v != "" {
-		p.VcIdxF = v
-	}
-
-	if v := r.FormValue("vc_id_f"); v != "" {
-		p.VcIDF = v
-	}
-
-	if v := r.FormValue("peer_ifalias_f"); v != "" {
-		p.PeerIfaliasF = &v
-	}
-
-	if v := r.FormValue("xname_f"); v != "" {
-		p.XnameF = &v
-	}
-
-	if v := r.FormValue("descr_f"); v != "" {
-		p.DescrF = &v
-	}
-
-	if v := r.FormValue("op_stat_f"); v != "" {
-		p.OpStatF = &v
-	}
-
-	if v := r.FormValue("op_stat_in_f"); v != "" {
-		p.OpStatInF = &v
-	}
-
-	if v := r.FormValue("op_stat_out_f"); v != "" {
-		p.OpStatOutF = &v
-	}
-
-	p.PeerIpF = strToPgInet(nil)
-	if v := r.FormValue("peer_ip_f"); v != "" {
-		p.PeerIpF = strToPgInet(&v)
+	// Query DB
+	l, err := parseListRequest(r, &xconnectsList)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// Query DB
-	q := godevmandb.New(h.db)
-	res, err := q.GetXconnects(h.ctx, p)
+	res, page, err := listRows[godevmandb.Xconnect](h, l)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err.Error())
 		return
@@ -211,7 +206,7 @@ func (h *Handler) GetXconnects(w http.ResponseWriter, r *http.Request) {
 		out = append(out, a)
 	}
 
-	RespondJSON(w, r, http.StatusOK, out)
+	respondList(w, r, page, out)
 }
 
 // Get Xconnect
@@ -220,7 +215,10 @@ func (h *Handler) GetXconnects(w http.ResponseWriter, r *http.Request) {
 // @Tags devices
 // @ID get-xconnect
 // @Param xc_id path string true "xc_id"
+// @Param If-None-Match header string false "ETag of cached resource state. Returns 304 if resource has not changed"
 // @Success 200 {object} xconnect
+// @Success 304
+// @Header 200 {string} ETag "entity tag of resource state"
 // @Failure 400 {object} StatusResponse "Invalid xc_id"
 // @Failure 404 {object} StatusResponse "Xconnect not found"
 // @Failure 405 {object} StatusResponse "Invalid method error"
@@ -295,10 +293,13 @@ func (h *Handler) CreateXconnect(w http.ResponseWriter, r *http.Request) {
 // @ID update-xconnect
 // @Param xc_id path string true "xc_id"
 // @Param Body body xconnect true "JSON object of xconnect.<br />Ignored fields:<ul><li>xc_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 200 {object} xconnect
+// @Header 200 {string} ETag "entity tag of updated resource state"
 // @Failure 400 {object} StatusResponse "Invalid request"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/xconnects/{xc_id} [PUT]
 func (h *Handler) UpdateXconnect(w http.ResponseWriter, r *http.Request) {
@@ -334,16 +335,39 @@ func (h *Handler) UpdateXconnect(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, r, http.StatusOK, out)
 }
 
+// Patch Xconnect
+// @Summary Patch xconnect
+// @Description Partially update xconnect with JSON merge patch (RFC 7396). Fields missing from patch keep their values
+// @Tags devices
+// @ID patch-xconnect
+// @Param xc_id path string true "xc_id"
+// @Param Body body xconnect true "JSON merge patch of xconnect.<br />Ignored fields:<ul><li>xc_id</li><li>updated_on</li><li>created_on</li></ul>"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
+// @Success 200 {object} xconnect
+// @Header 200 {string} ETag "entity tag of updated resource state"
+// @Failure 400 {object} StatusResponse "Invalid request"
+// @Failure 404 {object} StatusResponse "Invalid route error"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 415 {object} StatusResponse "Unsupported media type"
+// @Failure 412 {object} StatusResponse "Precondition failed"
+// @Failure 500 {object} StatusResponse "Failed DB transaction"
+// @Router /devices/xconnects/{xc_id} [PATCH]
+func (h *Handler) PatchXconnect(w http.ResponseWriter, r *http.Request) {
+	h.mergePatch(w, r, h.GetXconnect, h.UpdateXconnect)
+}
+
 // Delete Xconnect
 // @Summary Delete xconnect
 // @Description Delete xconnect
 // @Tags devices
 // @ID delete-xconnect
 // @Param xc_id path string true "xc_id"
+// @Param If-Match header string false "ETag of resource state. Request fails with 412 if resource has changed"
 // @Success 204
 // @Failure 400 {object} StatusResponse "Invalid xc_id"
 // @Failure 404 {object} StatusResponse "Invalid route error"
 // @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 412 {object} StatusResponse "Precondition failed"
 // @Failure 500 {object} StatusResponse "Failed DB transaction"
 // @Router /devices/xconnects/{xc_id} [DELETE]
 func (h *Handler) DeleteXconnect(w http.ResponseWriter, r *http.Request) {
@@ -363,6 +387,67 @@ func (h *Handler) DeleteXconnect(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Bulk operations of xconnects
+var xconnectsBulk = bulkSpec{
+	scope: ScopeXconnect,
+	audit: AuditXconnect,
+	create: func(h *Handler, q *godevmandb.Queries, b []byte) (int64, any, error) {
+		var pIn xconnect
+		if err := bulkDecode(b, &pIn); err != nil {
+			return 0, nil, err
+		}
+
+		res, err := q.CreateXconnect(h.ctx, pIn.createParams())
+		if err != nil {
+			return 0, nil, err
+		}
+
+		out := xconnect{}
+		out.getValues(res)
+
+		return res.XcID, out, nil
+	},
+	update: func(h *Handler, q *godevmandb.Queries, id int64, b []byte) (any, error) {
+		var pIn xconnect
+		if err := bulkDecode(b, &pIn); err != nil {
+			return nil, err
+		}
+
+		p := pIn.updateParams()
+		p.XcID = id
+
+		res, err := q.UpdateXconnect(h.ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		out := xconnect{}
+		out.getValues(res)
+
+		return out, nil
+	},
+	delete: func(h *Handler, q *godevmandb.Queries, id int64) error {
+		return q.DeleteXconnect(h.ctx, id)
+	},
+}
+
+// Bulk Xconnects
+// @Summary Bulk change xconnects
+// @Description Create, update and delete xconnects in single transaction. Items are applied in order. If any item fails, all changes are rolled back and response status is status of failed item
+// @Tags devices
+// @ID bulk-xconnects
+// @Param Body body []bulkItem true "JSON array of bulkItem. Data of create and update items is JSON object of xconnect"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} BulkResponse "Invalid request"
+// @Failure 403 {object} BulkResponse "Access denied"
+// @Failure 404 {object} BulkResponse "Row not found"
+// @Failure 405 {object} StatusResponse "Invalid method error"
+// @Failure 500 {object} BulkResponse "Failed DB transaction"
+// @Router /devices/xconnects/bulk [POST]
+func (h *Handler) BulkXconnects(w http.ResponseWriter, r *http.Request) {
+	h.bulk(w, r, xconnectsBulk)
+}
+
 // Foreign key
 // Get Xconnect Device
 // @Summary Get xconnect device
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/aretaja/godevmanapi/app"
 )
@@ -22,9 +28,39 @@ const version string = "v0.0.1-devel.1"
 func main() {
 	log.SetFlags(log.Ldate | log.Lmicroseconds | log.Lshortfile)
 
+	newToken := flag.String("new-token", "", "create API token for `username` and exit")
+	rotateKey := flag.Bool("rotate-key", false, "re-encrypt credential secrets with new key read from stdin and exit")
+	flag.Parse()
+
 	a := new(app.App)
 	a.Version = version
 	a.Initialize()
 
+	if *newToken != "" {
+		t, err := a.Handler.NewUserToken(*newToken, "created by command line")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(t)
+		return
+	}
+
+	if *rotateKey {
+		k, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && k == "" {
+			log.Fatal(err)
+		}
+
+		res, err := a.Handler.RotateKey(strings.TrimSpace(k))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		out, _ := json.Marshal(res)
+		fmt.Println(string(out))
+		return
+	}
+
 	a.Run()
 }
@@ -0,0 +1,50 @@
+-- Tables used by goDevmanAPI in addition to godevmandb schema
+
+-- API tokens
+CREATE TABLE IF NOT EXISTS user_tokens (
+    token_id bigserial PRIMARY KEY,
+    username text NOT NULL REFERENCES users (username) ON UPDATE CASCADE ON DELETE CASCADE,
+    descr text NOT NULL DEFAULT '',
+    token_hash text NOT NULL UNIQUE,
+    expires_on timestamp with time zone,
+    last_used_on timestamp with time zone,
+    created_on timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- Audit log
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_id bigserial PRIMARY KEY,
+    username text NOT NULL,
+    action text NOT NULL,
+    route text NOT NULL,
+    resource text NOT NULL,
+    resource_id text NOT NULL DEFAULT '',
+    before jsonb,
+    after jsonb,
+    changes jsonb,
+    created_on timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_on_idx ON audit_log (created_on);
+CREATE INDEX IF NOT EXISTS audit_log_resource_idx ON audit_log (resource, resource_id);
+
+-- Audit log is append-only
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- Topology link lookups by neighbour name and IP address
+CREATE INDEX IF NOT EXISTS devices_lower_sys_name_idx ON devices (lower(sys_name));
+CREATE INDEX IF NOT EXISTS devices_lower_host_name_idx ON devices (lower(host_name));
+CREATE INDEX IF NOT EXISTS devices_host_ip4_addr_idx ON devices (host(ip4_addr));
+CREATE INDEX IF NOT EXISTS devices_host_ip6_addr_idx ON devices (host(ip6_addr));
+CREATE INDEX IF NOT EXISTS ip_interfaces_host_ip_addr_idx ON ip_interfaces (host(ip_addr));
+CREATE INDEX IF NOT EXISTS rl_nbrs_lower_nbr_sysname_idx ON rl_nbrs (lower(nbr_sysname));
+CREATE INDEX IF NOT EXISTS ospf_nbrs_host_nbr_ip_idx ON ospf_nbrs (host(nbr_ip));